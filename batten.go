@@ -1,11 +1,15 @@
 package main
 
 import (
-	"os"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/Sirupsen/logrus"
 	"github.com/dockersecuritytools/batten/batten"
 	"github.com/dockersecuritytools/batten/cli"
-	"github.com/Sirupsen/logrus"
+	"github.com/mgutz/ansi"
 	"gopkg.in/alecthomas/kingpin.v1"
 )
 
@@ -16,41 +20,231 @@ const (
 )
 
 var (
-	app      = kingpin.New(Name, Description)
+	app = kingpin.New(Name, Description)
 	// appDebug = app.Flag("debug", "Enable debug mode.").Bool()
-	serverIP  = app.Flag("server", "Connect to remote host.").String()
-	tlscacert = app.Flag("tlscacert", "TLS CA Certificate.").String()
-	tlscert   = app.Flag("tlscert", "TLS Certificate.").String()
-	tlskey    = app.Flag("tlskey", "TLS Key.").String()
+	serverIP      = app.Flag("server", "Connect to remote host.").String()
+	dockerHost    = app.Flag("host", "Docker daemon to audit locally, e.g. unix:///run/user/1000/docker.sock. Defaults to $DOCKER_HOST or the current Docker context.").Short('H').String()
+	dockerContext = app.Flag("context", "Docker context of the daemon to audit locally.").String()
+	tlscacert     = app.Flag("tlscacert", "TLS CA Certificate.").String()
+	tlscert       = app.Flag("tlscert", "TLS Certificate.").String()
+	tlskey        = app.Flag("tlskey", "TLS Key.").String()
+	tlsSkipVerify = app.Flag("tls-skip-verify", "Do not verify the remote host's TLS certificate.").Bool()
+
+	scanImage        = app.Flag("image", "Scanner image to run on a remote host, optionally pinned with @sha256:<digest>.").Default(BattenDockerRepository).String()
+	scanPull         = app.Flag("pull", "When to pull the scanner image: always, missing or never.").Default(PullAlways).Enum(PullAlways, PullMissing, PullNever)
+	scanImageArchive = app.Flag("image-archive", "Load the scanner image from a 'docker save' archive instead of pulling it.").ExistingFile()
+
+	appCheck    = app.Command("check", "Check host for known issues.")
+	checkFormat = appCheck.Flag("format", "Output format: console, json, html, junit or sarif.").Default("console").Enum(cli.Formats...)
+	checkOutput = appCheck.Flag("output", "Write the results to a file instead of stdout.").String()
+	hostRoot    = appCheck.Flag("host-root", "Audit the host whose root filesystem is mounted at this path.").Default("/").String()
+	agentless   = appCheck.Flag("agentless", "With --server, audit the host through the Docker API only, without starting a scan container.").Bool()
+	policyFile  = appCheck.Flag("policy", "YAML policy of what the checks allow, such as the capabilities of containers.").ExistingFile()
+	checkFilter = newFilterFlags(appCheck)
 
-	appCheck = app.Command("check", "Check host for known issues.")
+	appList    = app.Command("list", "List the checks batten knows about.")
+	listFilter = newFilterFlags(appList)
+
+	appDaemons      = app.Command("daemons", "List the Docker daemons and containerd processes running on this host.")
+	daemonsHostRoot = appDaemons.Flag("host-root", "Read the daemons' files from the host whose root filesystem is mounted at this path.").Default("/").String()
+
+	appExplain   = app.Command("explain", "Show the full definition of a check.")
+	explainCheck = appExplain.Arg("id", "Check identifier, e.g. CIS-Docker-Benchmark-5.4.").Required().String()
+
+	appReport    = app.Command("report", "Render results saved with '--format json' into another format.")
+	reportInput  = appReport.Flag("input", "JSON results file.").Required().ExistingFile()
+	reportFormat = appReport.Flag("format", "Output format: console, json, html, junit or sarif.").Default("console").Enum(cli.Formats...)
+	reportOutput = appReport.Flag("output", "Write the report to a file instead of stdout.").String()
+
+	appFleet       = app.Command("fleet", "Scan every host in an inventory and report on all of them.")
+	fleetInventory = appFleet.Flag("inventory", "YAML inventory of the hosts to scan.").Required().ExistingFile()
+	fleetWorkers   = appFleet.Flag("workers", "Number of hosts scanned at the same time.").Default("10").Int()
+	fleetTimeout   = appFleet.Flag("timeout", "Give up on a host after this long.").Default("10m").Duration()
+	fleetFormat    = appFleet.Flag("format", "Output format: console or json.").Default("console").Enum(cli.FleetFormats...)
+	fleetOutput    = appFleet.Flag("output", "Write the fleet report to a file instead of stdout.").String()
+
+	appRemote          = app.Command("remote", "Manage what remote scans leave on a host.")
+	appRemoteCleanup   = appRemote.Command("cleanup", "Remove scan containers left behind by interrupted scans.")
+	remoteCleanupOlder = appRemoteCleanup.Flag("older-than", "Only remove scan containers created longer ago than this.").Default("1h").Duration()
 )
 
 func fatalf(format string, args ...interface{}) {
-	fmt.Printf("* fatal: "+format+"\n", args...)
+	fmt.Fprintf(os.Stderr, "* fatal: "+format+"\n", args...)
 	os.Exit(1)
 }
 
+// filterFlags are the flags that select checks, which `check` and `list`
+// share.
+type filterFlags struct {
+	ids      *[]string
+	sections *[]string
+	level    *string
+	severity *string
+}
+
+func newFilterFlags(cmd *kingpin.CmdClause) *filterFlags {
+	return &filterFlags{
+		ids:      cmd.Flag("id", "Only the check with this identifier, e.g. 5.4, or matching it, e.g. 'Batten-Container-*'. Repeatable.").Strings(),
+		sections: cmd.Flag("section", "Only the checks whose section contains this, e.g. 'container runtime'. Repeatable.").Strings(),
+		level:    cmd.Flag("level", "Only the checks of CIS profile level 1, or of levels 1 and 2.").Enum("1", "2"),
+		severity: cmd.Flag("severity", "Only the checks of this severity or higher: low, medium or high.").Enum(batten.Severities...),
+	}
+}
+
+func (f *filterFlags) filter() *batten.CheckFilter {
+	level, _ := strconv.Atoi(*f.level)
+	return &batten.CheckFilter{
+		IDs:      *f.ids,
+		Sections: *f.sections,
+		Level:    level,
+		Severity: *f.severity,
+	}
+}
+
 func init() {
 	logrus.SetLevel(logrus.DebugLevel)
 	logrus.SetOutput(os.Stderr)
 }
 
+// writeReport renders `report` to `output`, or to stdout if no output
+// file was given. Console output is only colored on a terminal.
+func writeReport(report *cli.Report, format string, output string) {
+	f := os.Stdout
+	if output != "" {
+		var err error
+		if f, err = os.Create(output); err != nil {
+			fatalf("Failed to create '%s'. Error: %v", output, err)
+		}
+	}
+	var w io.Writer = f
+	if format == "console" && !isTerminal(f) {
+		w = cli.NewPlainWriter(f)
+	}
+	if err := cli.WriteReport(w, format, report); err != nil {
+		fatalf("Failed to write %s report. Error: %v", format, err)
+	}
+	if output != "" {
+		if err := f.Close(); err != nil {
+			fatalf("Failed to write '%s'. Error: %v", output, err)
+		}
+	}
+}
+
+// isTerminal returns true if `f` is a terminal rather than a file or a
+// pipe.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// runChecks runs the checks selected on the command line with `run` and
+// presents the results of `host` in the format chosen there.
+func runChecks(host string, run func(batten.Check) *batten.CheckResults) {
+	checks := checkFilter.filter().Select(batten.Checks)
+	if len(checks) == 0 {
+		fatalf("No check matches the filters. Run 'batten list' to see all checks.")
+	}
+
+	if *checkFormat == "console" && *checkOutput == "" {
+		for i, check := range checks {
+			results := run(check)
+			cli.FormatResultsForConsole(i, len(checks), results)
+		}
+		return
+	}
+
+	var results []*batten.CheckResults
+	for i, check := range checks {
+		results = append(results, run(check))
+		cli.FormatProgressForConsole(os.Stderr, i, len(checks), results[i])
+	}
+	writeReport(cli.NewReport(host, Version, results), *checkFormat, *checkOutput)
+}
+
+// localCheck audits this machine and the daemon it runs.
+func localCheck() {
+	endpoint, err := batten.ResolveEndpoint(*dockerHost, *dockerContext)
+	if err != nil {
+		fatalf("Failed to find the Docker daemon. Error: %v", err)
+	}
+	if err := batten.UseDockerEndpoint(endpoint); err != nil {
+		fatalf("Failed to connect to '%s' (from %s). Error: %v", endpoint.Host, endpoint.Source, err)
+	}
+	if endpoint.SocketPath() == "" {
+		colorPrint(ansi.Yellow, "The Docker API of '%s' (from %s) is audited, but files and processes are checked on this machine. Use --server to audit a remote host.",
+			endpoint.Host, endpoint.Source)
+	}
+
+	batten.SetHostRoot(*hostRoot)
+	if daemons, err := batten.FindDaemons(); err == nil && len(daemons) > 1 {
+		colorPrint(ansi.Yellow, "%d Docker daemons are running; the daemon checks are run for each of them. Run 'batten daemons' to list them.", len(daemons))
+	}
+	host, _ := os.Hostname()
+	runChecks(host, batten.RunCheck)
+}
+
+// listDaemons lists the daemons running on this machine.
+func listDaemons(root string) {
+	batten.SetHostRoot(root)
+	daemons, err := batten.FindDaemons()
+	if err != nil {
+		fatalf("Failed to look for Docker daemons. Error: %v", err)
+	}
+	containerd, err := batten.FindContainerd()
+	if err != nil {
+		fatalf("Failed to look for containerd. Error: %v", err)
+	}
+	cli.FormatDaemonsForConsole(os.Stdout, daemons, containerd)
+}
 
 func main() {
 	kingpin.Version(Version)
 	args, err := app.Parse(os.Args[1:])
-	
+
 	switch kingpin.MustParse(args, err) {
 	case appCheck.FullCommand():
-		if len(*serverIP) > 0 {
+		if *policyFile != "" {
+			if len(*serverIP) > 0 && !*agentless {
+				fatalf("--policy cannot be given to a scan container; use it with --agentless or on the host itself.")
+			}
+			policy, err := batten.ReadPolicy(*policyFile)
+			if err != nil {
+				fatalf("Failed to read policy '%s'. Error: %v", *policyFile, err)
+			}
+			batten.UsePolicy(policy)
+		}
+		if len(*serverIP) > 0 && *agentless {
+			agentlessCheck()
+		} else if len(*serverIP) > 0 {
 			remoteCheck()
 		} else {
-			for i, check := range batten.Checks {
-				results := batten.RunCheck(check)
-				cli.FormatResultsForConsole(i, results)
-			}
+			localCheck()
+		}
+	case appList.FullCommand():
+		cli.FormatCheckListForConsole(listFilter.filter().Select(batten.Checks))
+	case appDaemons.FullCommand():
+		listDaemons(*daemonsHostRoot)
+	case appExplain.FullCommand():
+		check := batten.FindCheck(*explainCheck)
+		if check == nil {
+			fatalf("Unknown check '%s'. Run 'batten list' to see all checks.", *explainCheck)
+		}
+		cli.FormatCheckDefinitionForConsole(check.GetCheckDefinition())
+	case appReport.FullCommand():
+		f, err := os.Open(*reportInput)
+		if err != nil {
+			fatalf("Failed to open '%s'. Error: %v", *reportInput, err)
+		}
+		report, err := cli.ReadReport(f)
+		f.Close()
+		if err != nil {
+			fatalf("Failed to read '%s'. Error: %v", *reportInput, err)
 		}
+		writeReport(report, *reportFormat, *reportOutput)
+	case appFleet.FullCommand():
+		fleetCheck(*fleetInventory, *fleetWorkers, *fleetTimeout, *fleetFormat, *fleetOutput)
+	case appRemoteCleanup.FullCommand():
+		remoteCleanup(*remoteCleanupOlder)
 	default:
 		app.Usage(os.Stdout)
 	}
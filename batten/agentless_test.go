@@ -0,0 +1,41 @@
+package batten
+
+import (
+	"testing"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func TestRunCheckAgentless(t *testing.T) {
+	server := newFakeDocker(t)
+	defer server.Stop()
+	server.run(t, "web", &docker.Config{Image: "nginx", Cmd: []string{"nginx"}}, &docker.HostConfig{CapAdd: []string{"SYS_ADMIN"}})
+
+	UseDockerClient(server.client)
+	defer UseDockerClient(nil)
+
+	tests := []struct {
+		identifier    string
+		success       bool
+		notApplicable bool
+	}{
+		{"1.1", false, true},
+		{"2.2", false, true},
+		{"2.7", true, false},
+		{"4.1", false, false},
+		{"5.4", false, false},
+	}
+
+	for _, test := range tests {
+		results := RunCheckAgentless(FindCheck(test.identifier))
+		if results.Error != nil {
+			t.Errorf("%s: unexpected error: %v", test.identifier, results.Error)
+		}
+		if results.NotApplicable != test.notApplicable {
+			t.Errorf("%s: expected not applicable to be %v", test.identifier, test.notApplicable)
+		}
+		if results.Success != test.success {
+			t.Errorf("%s: expected success to be %v", test.identifier, test.success)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package batten
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// cgroupRoot is where the host mounts its cgroup hierarchies, or the
+// unified hierarchy of cgroup v2.
+const cgroupRoot = "/sys/fs/cgroup"
+
+// processCgroup is where the cgroups of a process are, as listed in its
+// /proc/<pid>/cgroup: a directory per controller with cgroup v1, and one
+// directory in the unified hierarchy with cgroup v2. A controller whose
+// cgroup could not be found on the host has no directory, and its path is
+// in `unresolved`.
+type processCgroup struct {
+	controllers map[string]string
+	unified     string
+	unresolved  []string
+}
+
+// readProcessCgroup reads the cgroups of the process `pid`, or returns nil
+// if there is no such process on this host.
+func readProcessCgroup(pid int) (*processCgroup, error) {
+	data, err := ioutil.ReadFile(path.Join(procRoot, strconv.Itoa(pid), "cgroup"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// hierarchy-ID:controller-list:cgroup-path
+	var lines [][]string
+	hybrid := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if fields[0] != "0" {
+			hybrid = true
+		}
+		lines = append(lines, fields)
+	}
+
+	cg := &processCgroup{controllers: make(map[string]string)}
+	for _, fields := range lines {
+		dir, ok := resolveCgroup(hierarchyDir(fields[1], hybrid), fields[2], pid)
+		if !ok {
+			cg.unresolved = append(cg.unresolved, fields[2])
+		}
+		if fields[0] == "0" && fields[1] == "" {
+			cg.unified = dir
+			continue
+		}
+		for _, controller := range strings.Split(fields[1], ",") {
+			cg.controllers[controller] = dir
+		}
+	}
+	return cg, nil
+}
+
+// hierarchyDir returns where the hierarchy of `controllers`, as listed in
+// /proc/<pid>/cgroup, is mounted. The unified hierarchy is mounted below
+// those of cgroup v1 when there are both.
+func hierarchyDir(controllers string, hybrid bool) string {
+	switch {
+	case controllers == "" && hybrid:
+		return path.Join(cgroupRoot, "unified")
+	case controllers == "":
+		return cgroupRoot
+	case strings.HasPrefix(controllers, "name="):
+		return path.Join(cgroupRoot, strings.TrimPrefix(controllers, "name="))
+	}
+	return path.Join(cgroupRoot, controllers)
+}
+
+// resolveCgroup returns the directory of the cgroup `cgroupPath` of the
+// process `pid` in the hierarchy mounted at `dir`. The path is relative to
+// the cgroup namespace of the reader, so that batten, run in a container
+// with a cgroup namespace of its own, sees e.g.
+// /../../system.slice/docker-<id>.scope. Such a path climbs to the root of
+// the hierarchy when the namespace is that of a container, and is resolved
+// by leaving out the climb, but only if the process is found in the cgroup
+// there.
+func resolveCgroup(dir string, cgroupPath string, pid int) (string, bool) {
+	if cgroupPath != "/.." && !strings.HasPrefix(cgroupPath, "/../") {
+		return path.Join(dir, cgroupPath), true
+	}
+	for cgroupPath == "/.." || strings.HasPrefix(cgroupPath, "/../") {
+		cgroupPath = strings.TrimPrefix(cgroupPath, "/..")
+	}
+	dir = path.Join(dir, cgroupPath)
+	data, err := ioutil.ReadFile(hostPath(path.Join(dir, "cgroup.procs")))
+	if err != nil {
+		return "", false
+	}
+	for _, line := range strings.Fields(string(data)) {
+		if line == strconv.Itoa(pid) {
+			return dir, true
+		}
+	}
+	return "", false
+}
+
+// read returns the value of a file of the process's cgroup: `v1file` of
+// the hierarchy of `controller`, or `v2file` of the unified hierarchy when
+// the controller has none of its own. It returns false if there is no such
+// file, e.g. when the controller is not enabled.
+func (cg *processCgroup) read(controller string, v1file string, v2file string) (string, bool) {
+	dir, file := cg.unified, v2file
+	if v1dir, ok := cg.controllers[controller]; ok {
+		dir, file = v1dir, v1file
+	}
+	if dir == "" {
+		return "", false
+	}
+	filename := path.Join(dir, file)
+	data, err := ioutil.ReadFile(hostPath(filename))
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(data)), true
+}
+
+// v1 reports whether `controller` has a hierarchy of its own.
+func (cg *processCgroup) v1(controller string) bool {
+	_, ok := cg.controllers[controller]
+	return ok
+}
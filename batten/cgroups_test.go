@@ -0,0 +1,98 @@
+package batten
+
+import (
+	"io/ioutil"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestReadProcessCgroup(t *testing.T) {
+	defer withProcesses(t, map[int]fakeProcess{2001: {cmdline: "nginx"}})()
+	defer withHostFiles(t, map[string]string{
+		"/sys/fs/cgroup/system.slice/docker-a1.scope/cgroup.procs": "2001\n2044\n",
+		"/sys/fs/cgroup/memory/docker/a1/cgroup.procs":             "2001\n",
+		"/sys/fs/cgroup/pids/docker/a1/cgroup.procs":               "2044\n",
+	})()
+
+	tests := []struct {
+		cgroup   string
+		expected *processCgroup
+	}{
+		// cgroup v2
+		{
+			"0::/system.slice/docker-a1.scope\n",
+			&processCgroup{
+				controllers: map[string]string{},
+				unified:     "/sys/fs/cgroup/system.slice/docker-a1.scope",
+			},
+		},
+		// cgroup v1
+		{
+			"12:pids:/docker/a1\n6:memory:/docker/a1\n4:cpu,cpuacct:/docker/a1\n1:name=systemd:/docker/a1\n",
+			&processCgroup{
+				controllers: map[string]string{
+					"pids":         "/sys/fs/cgroup/pids/docker/a1",
+					"memory":       "/sys/fs/cgroup/memory/docker/a1",
+					"cpu":          "/sys/fs/cgroup/cpu,cpuacct/docker/a1",
+					"cpuacct":      "/sys/fs/cgroup/cpu,cpuacct/docker/a1",
+					"name=systemd": "/sys/fs/cgroup/systemd/docker/a1",
+				},
+			},
+		},
+		// hybrid, with the unified hierarchy below those of cgroup v1
+		{
+			"6:memory:/docker/a1\n1:name=systemd:/docker/a1\n0::/docker/a1\n",
+			&processCgroup{
+				controllers: map[string]string{
+					"memory":       "/sys/fs/cgroup/memory/docker/a1",
+					"name=systemd": "/sys/fs/cgroup/systemd/docker/a1",
+				},
+				unified: "/sys/fs/cgroup/unified/docker/a1",
+			},
+		},
+		// read from the cgroup namespace of another container
+		{
+			"0::/../../system.slice/docker-a1.scope\n",
+			&processCgroup{
+				controllers: map[string]string{},
+				unified:     "/sys/fs/cgroup/system.slice/docker-a1.scope",
+			},
+		},
+		// the process is not in the cgroup the path resolves to
+		{
+			"6:memory:/../docker/a1\n12:pids:/../docker/a1\n",
+			&processCgroup{
+				controllers: map[string]string{
+					"memory": "/sys/fs/cgroup/memory/docker/a1",
+					"pids":   "",
+				},
+				unresolved: []string{"/../docker/a1"},
+			},
+		},
+		// the namespace does not climb to the root of the hierarchy
+		{
+			"0::/../docker-a1.scope\n",
+			&processCgroup{
+				controllers: map[string]string{},
+				unresolved:  []string{"/../docker-a1.scope"},
+			},
+		},
+	}
+	for _, test := range tests {
+		if err := ioutil.WriteFile(path.Join(procRoot, "2001", "cgroup"), []byte(test.cgroup), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cg, err := readProcessCgroup(2001)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(cg, test.expected) {
+			t.Errorf("%s: got %+v, expected %+v", test.cgroup, cg, test.expected)
+		}
+	}
+
+	if cg, err := readProcessCgroup(2002); cg != nil || err != nil {
+		t.Errorf("expected no cgroup for a process that is not on the host, got %+v, %v", cg, err)
+	}
+}
@@ -0,0 +1,85 @@
+package batten
+
+import (
+	"path"
+	"strings"
+)
+
+// CheckFilter selects checks by their identifier, section, level and
+// severity. Its zero value selects every check.
+type CheckFilter struct {
+	// IDs are identifiers, in full or as the benchmark number alone, and
+	// may have path.Match wildcards, e.g. `5.*`.
+	IDs []string
+	// Sections select the checks whose section contains any of them,
+	// regardless of case.
+	Sections []string
+	// Level is the highest level selected, or 0 for every level.
+	Level int
+	// Severity is the lowest severity selected, or "" for every severity.
+	Severity string
+}
+
+// Matches returns true if the filter selects the check with the given
+// definition.
+func (f *CheckFilter) Matches(identifier string, section string, level int, severity string) bool {
+	if len(f.IDs) > 0 && !matchesAnyID(f.IDs, identifier) {
+		return false
+	}
+	if len(f.Sections) > 0 {
+		found := false
+		for _, s := range f.Sections {
+			if strings.Contains(strings.ToLower(section), strings.ToLower(s)) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Level > 0 && level > f.Level {
+		return false
+	}
+	if f.Severity != "" && severityRank(severity) < severityRank(f.Severity) {
+		return false
+	}
+	return true
+}
+
+// Select returns the checks of `checks` the filter selects, in order.
+func (f *CheckFilter) Select(checks []Check) []Check {
+	var selected []Check
+	for _, check := range checks {
+		def := check.GetCheckDefinition()
+		if f.Matches(def.Identifier(), def.Category(), def.Level(), def.Severity()) {
+			selected = append(selected, check)
+		}
+	}
+	return selected
+}
+
+func matchesAnyID(patterns []string, identifier string) bool {
+	id := strings.ToLower(identifier)
+	number := strings.TrimPrefix(id, strings.ToLower(CISBenchmarkPrefix))
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if ok, _ := path.Match(pattern, id); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, number); ok && number != id {
+			return true
+		}
+	}
+	return false
+}
+
+// severityRank orders severities, taking those it does not know, such as
+// the missing severity of a report from an older batten, as medium.
+func severityRank(severity string) int {
+	for i, s := range Severities {
+		if s == severity {
+			return i
+		}
+	}
+	return 1
+}
@@ -0,0 +1,35 @@
+package batten
+
+import "testing"
+
+func TestCheckFilterMatches(t *testing.T) {
+	tests := []struct {
+		filter   CheckFilter
+		id       string
+		section  string
+		level    int
+		severity string
+		expected bool
+	}{
+		{CheckFilter{}, "CIS-Docker-Benchmark-5.4", "Container Runtime", 1, SeverityHigh, true},
+		{CheckFilter{IDs: []string{"5.4"}}, "CIS-Docker-Benchmark-5.4", "Container Runtime", 1, SeverityHigh, true},
+		{CheckFilter{IDs: []string{"5.4"}}, "CIS-Docker-Benchmark-5.14", "Container Runtime", 1, SeverityLow, false},
+		{CheckFilter{IDs: []string{"5.*"}}, "CIS-Docker-Benchmark-5.14", "Container Runtime", 1, SeverityLow, true},
+		{CheckFilter{IDs: []string{"batten-container-*"}}, "Batten-Container-Privileged", "Container Runtime", 1, SeverityHigh, true},
+		{CheckFilter{IDs: []string{"cis-docker-benchmark-2.1"}}, "CIS-Docker-Benchmark-2.1", "Docker daemon configuration", 1, SeverityLow, true},
+		{CheckFilter{Sections: []string{"daemon configuration"}}, "CIS-Docker-Benchmark-2.1", "Docker daemon configuration", 1, SeverityLow, true},
+		{CheckFilter{Sections: []string{"daemon configuration"}}, "CIS-Docker-Benchmark-5.4", "Container Runtime", 1, SeverityHigh, false},
+		{CheckFilter{Level: 1}, "Batten-Daemon-Userns-Remap", "Docker daemon configuration", 2, SeverityMedium, false},
+		{CheckFilter{Level: 2}, "Batten-Daemon-Userns-Remap", "Docker daemon configuration", 2, SeverityMedium, true},
+		{CheckFilter{Severity: SeverityMedium}, "CIS-Docker-Benchmark-6.6", "Docker Security Operations", 1, SeverityLow, false},
+		{CheckFilter{Severity: SeverityMedium}, "CIS-Docker-Benchmark-5.4", "Container Runtime", 1, SeverityHigh, true},
+		{CheckFilter{Severity: SeverityMedium}, "CIS-Docker-Benchmark-5.4", "Container Runtime", 0, "", true},
+		{CheckFilter{Severity: SeverityHigh}, "CIS-Docker-Benchmark-5.4", "Container Runtime", 0, "", false},
+	}
+	for _, test := range tests {
+		if got := test.filter.Matches(test.id, test.section, test.level, test.severity); got != test.expected {
+			t.Errorf("%+v matching %s (%s, level %d, %s): got %v, expected %v",
+				test.filter, test.id, test.section, test.level, test.severity, got, test.expected)
+		}
+	}
+}
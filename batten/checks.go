@@ -1,5 +1,20 @@
 package batten
 
+import "strings"
+
+// CISBenchmarkPrefix prefixes the identifier of every CIS Docker Benchmark check.
+const CISBenchmarkPrefix = "CIS-Docker-Benchmark-"
+
+// Severities rate how much a failed check exposes the host, from least to
+// most.
+const (
+	SeverityLow    = "low"
+	SeverityMedium = "medium"
+	SeverityHigh   = "high"
+)
+
+var Severities = []string{SeverityLow, SeverityMedium, SeverityHigh}
+
 type CheckDefinitionImpl struct {
 	name             string
 	category         string
@@ -11,6 +26,12 @@ type CheckDefinitionImpl struct {
 	references       []string
 	auditDescription string
 	identifier       string
+
+	// level is the CIS profile level of the check: 1 for what every host
+	// should do, 2 for defense in depth that may get in the way. It is
+	// level 1 and of medium severity unless set.
+	level    int
+	severity string
 }
 
 func (c *CheckDefinitionImpl) Category() string {
@@ -52,8 +73,23 @@ func (c *CheckDefinitionImpl) References() []string {
 	return c.references
 }
 
+func (c *CheckDefinitionImpl) Level() int {
+	if c.level == 0 {
+		return 1
+	}
+	return c.level
+}
+
+func (c *CheckDefinitionImpl) Severity() string {
+	if c.severity == "" {
+		return SeverityMedium
+	}
+	return c.severity
+}
+
 type CheckDefinition interface {
 	Identifier() string
+	Category() string
 	Name() string
 	Description() string
 	Rationale() string
@@ -61,6 +97,9 @@ type CheckDefinition interface {
 	Impact() string
 	DefaultValue() string
 	References() []string
+	AuditDescription() string
+	Level() int
+	Severity() string
 }
 
 type Check interface {
@@ -68,13 +107,85 @@ type Check interface {
 	GetCheckDefinition() CheckDefinition
 }
 
+// DockerAPICheck is embedded by checks that are evaluated through the
+// Docker remote API alone, without reading the host's files or processes.
+type DockerAPICheck struct{}
+
+func (DockerAPICheck) UsesDockerAPIOnly() bool {
+	return true
+}
+
+type dockerAPIOnly interface {
+	UsesDockerAPIOnly() bool
+}
+
+// DaemonCheck is embedded by checks that audit the options of a Docker
+// daemon. When the host runs more than one daemon, they are run for each.
+type DaemonCheck struct{}
+
+func (DaemonCheck) AuditsDaemon() bool {
+	return true
+}
+
+type daemonCheck interface {
+	AuditsDaemon() bool
+}
+
+// applicableCheck is implemented by checks that do not apply to every
+// configuration, and are reported as not applicable instead of being run
+// when they do not.
+type applicableCheck interface {
+	Applicable() bool
+}
+
+// Finding is one thing a check found wrong, e.g. a container running
+// privileged, so that a report can say what needs to be fixed.
+type Finding struct {
+	Subject string
+	Detail  string
+}
+
+// findingsCheck is implemented by checks that report what made them
+// fail, and not only whether they did.
+type findingsCheck interface {
+	AuditFindings() (bool, []Finding, error)
+}
+
 type CheckResults struct {
 	Success         bool
+	NotApplicable   bool
 	Error           error
+	Findings        []Finding
 	CheckDefinition CheckDefinition
 }
 
 func RunCheck(c Check) *CheckResults {
+	if check, ok := c.(applicableCheck); ok && !check.Applicable() {
+		return &CheckResults{
+			NotApplicable:   true,
+			CheckDefinition: c.GetCheckDefinition(),
+		}
+	}
+	if check, ok := c.(daemonCheck); ok && check.AuditsDaemon() {
+		if daemons, err := FindDaemons(); err == nil && len(daemons) > 1 {
+			return runCheckForDaemons(c, daemons)
+		}
+	}
+	return runCheck(c)
+}
+
+func runCheck(c Check) *CheckResults {
+	if check, ok := c.(findingsCheck); ok {
+		succ, findings, err := check.AuditFindings()
+
+		return &CheckResults{
+			Success:         succ,
+			Error:           err,
+			Findings:        findings,
+			CheckDefinition: c.GetCheckDefinition(),
+		}
+	}
+
 	succ, err := c.AuditCheck()
 
 	return &CheckResults{
@@ -84,6 +195,76 @@ func RunCheck(c Check) *CheckResults {
 	}
 }
 
+// runCheckForDaemons runs `c` once for every daemon. It fails if it fails
+// for any of them, with findings saying for which.
+func runCheckForDaemons(c Check, daemons []*DaemonProcess) *CheckResults {
+	results := &CheckResults{
+		Success:         true,
+		CheckDefinition: c.GetCheckDefinition(),
+	}
+
+	var errs []error
+	for _, daemon := range daemons {
+		auditedDaemon = daemon
+		r := runCheck(c)
+		auditedDaemon = nil
+
+		switch {
+		case r.Error != nil:
+			errs = append(errs, r.Error)
+			results.Success = false
+			results.Findings = append(results.Findings, Finding{
+				Subject: daemon.String(),
+				Detail:  "could not be audited: " + r.Error.Error(),
+			})
+		case !r.Success && len(r.Findings) == 0:
+			results.Success = false
+			results.Findings = append(results.Findings, Finding{Subject: daemon.String()})
+		case !r.Success:
+			results.Success = false
+			for _, finding := range r.Findings {
+				results.Findings = append(results.Findings, Finding{
+					Subject: daemon.String() + ": " + finding.Subject,
+					Detail:  finding.Detail,
+				})
+			}
+		}
+	}
+
+	if len(errs) == len(daemons) {
+		// the check could not be run at all
+		results.Error = errs[0]
+		results.Findings = nil
+	}
+	return results
+}
+
+// RunCheckAgentless runs `c` the way an audit over the Docker API alone
+// does: checks that need access to the host's files or processes are not
+// run, and are reported as not applicable instead.
+func RunCheckAgentless(c Check) *CheckResults {
+	if check, ok := c.(dockerAPIOnly); !ok || !check.UsesDockerAPIOnly() {
+		return &CheckResults{
+			NotApplicable:   true,
+			CheckDefinition: c.GetCheckDefinition(),
+		}
+	}
+	return RunCheck(c)
+}
+
+// FindCheck returns the registered check with the given identifier, or nil
+// if there is none. The identifier may be given in full
+// (`CIS-Docker-Benchmark-5.4`) or as the benchmark number alone (`5.4`).
+func FindCheck(identifier string) Check {
+	for _, check := range Checks {
+		id := check.GetCheckDefinition().Identifier()
+		if strings.EqualFold(id, identifier) || strings.TrimPrefix(id, CISBenchmarkPrefix) == identifier {
+			return check
+		}
+	}
+	return nil
+}
+
 // TODO: put the checks in a diff package and
 // allow to register with the batten main package.
 var Checks []Check = []Check{
@@ -117,6 +298,17 @@ var Checks []Check = []Check{
 	makeDockerPortCheck(),
 	makeDockerTLSCheck(),
 	makeDockerUlimitCheck(),
+	makeDockerDaemonConfigDriftCheck(),
+	makeDockerUsernsRemapCheck(),
+	makeDockerUsernsSubIDsCheck(),
+	makeDockerAuthzPluginCheck(),
+	makeDockerTCPAuthzCheck(),
+	makeDockerLiveRestoreCheck(),
+	makeDockerUserlandProxyCheck(),
+	makeDockerNoNewPrivilegesCheck(),
+	makeDockerSeccompProfileCheck(),
+	makeDockerExperimentalCheck(),
+	makeDockerCgroupParentCheck(),
 	// 3.x Docker daemon configuration files
 	makeDockerSvcOwnerCheck(),
 	makeDockerSvcFilePermsCheck(),
@@ -144,6 +336,7 @@ var Checks []Check = []Check{
 	makeDockerTLSKeyFilePermsCheck(),
 	makeDockerSocketOwnerCheck(),
 	makeDockerSocketFilePermsCheck(),
+	makeDockerAuthzPluginFilesCheck(),
 
 	// 4.x
 	makeDockerContainerUserCheck(),
@@ -155,6 +348,32 @@ var Checks []Check = []Check{
 	makeDockerVerifySELinuxProfile(),
 	makeDockerSingleMainProcess(),
 	makeDockerRestrictKernel(),
+	makeDockerContainerUsernsCheck(),
+	makeDockerContainerPrivilegedCheck(),
+	makeDockerContainerNoNewPrivilegesCheck(),
+	makeDockerContainerUnconfinedCheck(),
+	makeDockerContainerAllCapabilitiesCheck(),
+	makeDockerHostNetworkCheck(),
+	makeDockerHostPidCheck(),
+	makeDockerHostIpcCheck(),
+	makeDockerHostUtsCheck(),
+	makeDockerHostUsernsCheck(),
+	makeDockerHostCgroupnsCheck(),
+	makeDockerContainerSensitiveMountsCheck(),
+	makeDockerContainerRuntimeSocketCheck(),
+	makeDockerContainerMountPropagationCheck(),
+	makeDockerContainerDevicesCheck(),
+	makeDockerContainerMemoryCheck(),
+	makeDockerContainerCPUCheck(),
+	makeDockerContainerPidsCheck(),
+	makeDockerContainerPrivilegedPortsCheck(),
+	makeDockerContainerPublishAllPortsCheck(),
+	makeDockerContainerBindInterfaceCheck(),
+	makeDockerContainerSensitivePortsCheck(),
+	makeDockerContainerExposedPortsCheck(),
+	makeDockerContainerReadonlyRootfsCheck(),
+	makeDockerContainerRestartPolicyCheck(),
+	makeDockerContainerHealthcheckCheck(),
 
 	// 6.x
 	makeDockerPerformSecurityAudits(),
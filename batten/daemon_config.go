@@ -0,0 +1,361 @@
+package batten
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	DaemonConfigFile = "/etc/docker/daemon.json"
+
+	sourceCommandLine = "the command line"
+)
+
+var errDaemonNotRunning = errors.New("Docker daemon not running")
+
+// daemonJSONKeys maps the daemon.json keys that are named differently from
+// the flags they stand for; every other key is named after its flag.
+var daemonJSONKeys = map[string]string{
+	"hosts":                 "host",
+	"insecure-registries":   "insecure-registry",
+	"registry-mirrors":      "registry-mirror",
+	"default-ulimits":       "default-ulimit",
+	"authorization-plugins": "authorization-plugin",
+	"labels":                "label",
+	"log-opts":              "log-opt",
+	"storage-opts":          "storage-opt",
+	"exec-opts":             "exec-opt",
+	"dns-opts":              "dns-opt",
+}
+
+// daemonOption is the value of a daemon option, and where it was set.
+// List options such as `--host` can have many values.
+type daemonOption struct {
+	values []string
+	source string
+}
+
+// DaemonConfig is the daemon's effective configuration: its command line
+// merged with its configuration file. Options are named after their long
+// flag, e.g. "icc" or "insecure-registry".
+type DaemonConfig struct {
+	options map[string]*daemonOption
+
+	// the configuration file that was read, if there was one
+	ConfigFile string
+
+	// options set both on the command line and in the configuration file,
+	// which the daemon refuses to start with
+	Conflicts []string
+
+	// whether the command line is the running daemon's, rather than the
+	// one it is configured to be started with
+	Running bool
+}
+
+// newDaemonConfig builds the configuration of a daemon started with `args`
+// and configured with the daemon.json contents `file`, which may be nil.
+func newDaemonConfig(args []string, configFile string, file []byte) (*DaemonConfig, error) {
+	config := &DaemonConfig{options: make(map[string]*daemonOption)}
+
+	for name, values := range parseDaemonArgs(args) {
+		config.options[name] = &daemonOption{values: values, source: sourceCommandLine}
+	}
+
+	if file == nil {
+		return config, nil
+	}
+	config.ConfigFile = configFile
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(file, &raw); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %v", configFile, err)
+	}
+	for key, value := range raw {
+		name := key
+		if flag, ok := daemonJSONKeys[key]; ok {
+			name = flag
+		}
+		if _, ok := config.options[name]; ok {
+			config.Conflicts = append(config.Conflicts, name)
+			continue
+		}
+		config.options[name] = &daemonOption{values: jsonOptionValues(value), source: configFile}
+	}
+	sort.Strings(config.Conflicts)
+	return config, nil
+}
+
+// jsonOptionValues converts a daemon.json value into the values it would
+// have been given on the command line.
+func jsonOptionValues(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case bool:
+		return []string{strconv.FormatBool(v)}
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			values = append(values, jsonOptionValues(item)...)
+		}
+		return values
+	case map[string]interface{}:
+		// maps such as "log-opts" are given as name=value on the command
+		// line, and "default-ulimits" as name=soft:hard
+		var values []string
+		for name, item := range v {
+			if ulimit, ok := item.(map[string]interface{}); ok && ulimit["Hard"] != nil {
+				soft, hard := jsonOptionValues(ulimit["Soft"]), jsonOptionValues(ulimit["Hard"])
+				values = append(values, fmt.Sprintf("%s=%s:%s", name, strings.Join(soft, ""), strings.Join(hard, "")))
+				continue
+			}
+			values = append(values, name+"="+strings.Join(jsonOptionValues(item), ","))
+		}
+		sort.Strings(values)
+		return values
+	}
+	data, _ := json.Marshal(value)
+	return []string{string(data)}
+}
+
+// readDaemonConfig reads the effective configuration of the daemon: its
+// command line, and the configuration file given with `--config-file` or
+// found at the daemon's default location. When the daemon is not running,
+// the command line it is configured to be started with is used instead.
+func readDaemonConfig(dockerPidFile string) (*DaemonConfig, error) {
+	daemon, err := findDaemon(dockerPidFile)
+	if err != nil {
+		return nil, err
+	}
+	if daemon == nil {
+		configured, err := readConfiguredDaemon()
+		if err != nil {
+			return nil, err
+		}
+		if configured == nil {
+			return nil, errDaemonNotRunning
+		}
+		daemon = &DaemonProcess{Args: configured.Args, ConfigFile: DaemonConfigFile}
+		if values := parseDaemonArgs(configured.Args)["config-file"]; len(values) > 0 {
+			daemon.ConfigFile = values[len(values)-1]
+		}
+	}
+
+	file, err := daemon.readFile(daemon.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	config, err := newDaemonConfig(daemon.Args, daemon.ConfigFile, file)
+	if err != nil {
+		return nil, err
+	}
+	config.Running = daemon.Pid > 0
+	return config, nil
+}
+
+// readHostFile reads a file of the audited host, returning nil if it
+// does not exist.
+func readHostFile(filename string) ([]byte, error) {
+	data, err := ioutil.ReadFile(hostPath(filename))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// conflicts returns an error if any of `names` is set both on the command
+// line and in the configuration file.
+func (c *DaemonConfig) conflicts(names ...string) error {
+	for _, name := range names {
+		for _, conflict := range c.Conflicts {
+			if conflict == name {
+				return fmt.Errorf("'%s' is set both on the command line and in %s", name, c.ConfigFile)
+			}
+		}
+	}
+	return nil
+}
+
+// IsSet returns true if the option was set at all.
+func (c *DaemonConfig) IsSet(name string) bool {
+	_, ok := c.options[name]
+	return ok
+}
+
+// Values returns every value the option was given.
+func (c *DaemonConfig) Values(name string) []string {
+	if option, ok := c.options[name]; ok {
+		return option.values
+	}
+	return nil
+}
+
+// Value returns the value of the option, or `def` if it is not set. An
+// option given more than once takes its last value.
+func (c *DaemonConfig) Value(name string, def string) string {
+	values := c.Values(name)
+	if len(values) == 0 {
+		return def
+	}
+	return values[len(values)-1]
+}
+
+// Bool returns the value of a boolean option, or `def` if it is not set.
+func (c *DaemonConfig) Bool(name string, def bool) (bool, error) {
+	if !c.IsSet(name) {
+		return def, nil
+	}
+	value, err := strconv.ParseBool(c.Value(name, ""))
+	if err != nil {
+		return false, fmt.Errorf("invalid value for '%s' in %s: %v", name, c.Source(name), err)
+	}
+	return value, nil
+}
+
+// Source returns where the option was set.
+func (c *DaemonConfig) Source(name string) string {
+	if option, ok := c.options[name]; ok {
+		return option.source
+	}
+	return ""
+}
+
+// DaemonOptions are the daemon options the checks evaluate, with the
+// daemon's defaults for those that are not set.
+type DaemonOptions struct {
+	ICC      bool
+	IPTables bool
+	LogLevel string
+
+	Hosts     []string
+	TLS       bool
+	TLSVerify bool
+	TLSCACert string
+	TLSCert   string
+	TLSKey    string
+
+	InsecureRegistries []string
+	RegistryMirrors    []string
+	DefaultUlimits     []string
+
+	UsernsRemap string
+
+	AuthorizationPlugins []string
+
+	LiveRestore     bool
+	UserlandProxy   bool
+	NoNewPrivileges bool
+	Experimental    bool
+	SeccompProfile  string
+	CgroupParent    string
+	CgroupDriver    string
+
+	LogDriver string
+	LogOpts   map[string]string
+}
+
+// Options returns the typed options of the configuration.
+func (c *DaemonConfig) Options() (*DaemonOptions, error) {
+	options := &DaemonOptions{
+		LogLevel:             c.Value("log-level", "info"),
+		Hosts:                c.Values("host"),
+		TLSCACert:            c.Value("tlscacert", ""),
+		TLSCert:              c.Value("tlscert", ""),
+		TLSKey:               c.Value("tlskey", ""),
+		InsecureRegistries:   c.Values("insecure-registry"),
+		RegistryMirrors:      c.Values("registry-mirror"),
+		DefaultUlimits:       c.Values("default-ulimit"),
+		UsernsRemap:          c.Value("userns-remap", ""),
+		AuthorizationPlugins: c.Values("authorization-plugin"),
+		SeccompProfile:       c.Value("seccomp-profile", ""),
+		CgroupParent:         c.Value("cgroup-parent", ""),
+		CgroupDriver:         "cgroupfs",
+		LogDriver:            c.Value("log-driver", "json-file"),
+		LogOpts:              make(map[string]string),
+	}
+	for _, opt := range c.Values("log-opt") {
+		if kv := strings.SplitN(opt, "=", 2); len(kv) == 2 {
+			options.LogOpts[kv[0]] = kv[1]
+		}
+	}
+	for _, opt := range c.Values("exec-opt") {
+		if kv := strings.SplitN(opt, "=", 2); len(kv) == 2 && strings.TrimSpace(kv[0]) == "native.cgroupdriver" {
+			options.CgroupDriver = strings.TrimSpace(kv[1])
+		}
+	}
+
+	bools := []struct {
+		name  string
+		def   bool
+		value *bool
+	}{
+		{"icc", true, &options.ICC},
+		{"iptables", true, &options.IPTables},
+		{"tls", false, &options.TLS},
+		{"tlsverify", false, &options.TLSVerify},
+		{"live-restore", false, &options.LiveRestore},
+		{"userland-proxy", true, &options.UserlandProxy},
+		{"no-new-privileges", false, &options.NoNewPrivileges},
+		{"experimental", false, &options.Experimental},
+	}
+	for _, b := range bools {
+		value, err := c.Bool(b.name, b.def)
+		if err != nil {
+			return nil, err
+		}
+		*b.value = value
+	}
+	return options, nil
+}
+
+// readDaemonFile reads a file of the daemon, such as its seccomp profile,
+// from its own root when it runs in a container. It returns nil if the file
+// does not exist.
+func readDaemonFile(dockerPidFile string, filename string) ([]byte, error) {
+	daemon, err := findDaemon(dockerPidFile)
+	if err != nil {
+		return nil, err
+	}
+	if daemon == nil {
+		return readHostFile(filename)
+	}
+	return daemon.readFile(filename)
+}
+
+// TCPHosts returns the addresses the daemon listens on other than unix
+// sockets and systemd socket activation, i.e. those reachable over the
+// network.
+func (o *DaemonOptions) TCPHosts() []string {
+	var hosts []string
+	for _, host := range o.Hosts {
+		if !strings.HasPrefix(host, "unix://") && !strings.HasPrefix(host, "fd://") {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// readDaemonOptions reads the daemon's effective options, failing if any
+// of `names` is set both on the command line and in the configuration
+// file.
+func readDaemonOptions(dockerPidFile string, names ...string) (*DaemonOptions, error) {
+	config, err := readDaemonConfig(dockerPidFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.conflicts(names...); err != nil {
+		return nil, err
+	}
+	return config.Options()
+}
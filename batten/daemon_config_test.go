@@ -0,0 +1,86 @@
+package batten
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDaemonConfigMergesConfigFile(t *testing.T) {
+	args := []string{"/usr/bin/dockerd", "-H", "fd://", "--log-level=debug"}
+	file := []byte(`{
+		"icc": false,
+		"tlsverify": true,
+		"insecure-registries": ["10.0.0.1:5000", "registry.local"],
+		"default-ulimits": {"nofile": {"Name": "nofile", "Hard": 1048576, "Soft": 1048576}},
+		"log-level": "info"
+	}`)
+
+	config, err := newDaemonConfig(args, DaemonConfigFile, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if icc, err := config.Bool("icc", true); err != nil || icc {
+		t.Errorf("expected icc to be false, got %v (%v)", icc, err)
+	}
+	if source := config.Source("icc"); source != DaemonConfigFile {
+		t.Errorf("expected icc to come from %s, got %s", DaemonConfigFile, source)
+	}
+	if iptables, err := config.Bool("iptables", true); err != nil || !iptables {
+		t.Errorf("expected iptables to default to true, got %v (%v)", iptables, err)
+	}
+	if hosts := config.Values("host"); !reflect.DeepEqual(hosts, []string{"fd://"}) {
+		t.Errorf("unexpected hosts %v", hosts)
+	}
+	if registries := config.Values("insecure-registry"); !reflect.DeepEqual(registries, []string{"10.0.0.1:5000", "registry.local"}) {
+		t.Errorf("unexpected insecure registries %v", registries)
+	}
+	if ulimits := config.Values("default-ulimit"); !reflect.DeepEqual(ulimits, []string{"nofile=1048576:1048576"}) {
+		t.Errorf("unexpected default ulimits %v", ulimits)
+	}
+
+	// dockerd refuses to start when an option is set in both places
+	if !reflect.DeepEqual(config.Conflicts, []string{"log-level"}) {
+		t.Errorf("unexpected conflicts %v", config.Conflicts)
+	}
+	if err := config.conflicts("icc", "log-level"); err == nil {
+		t.Errorf("expected a conflict for log-level")
+	}
+	if err := config.conflicts("icc"); err != nil {
+		t.Errorf("unexpected conflict: %v", err)
+	}
+}
+
+func TestDaemonConfigWithoutConfigFile(t *testing.T) {
+	config, err := newDaemonConfig([]string{"docker", "-d", "--icc=false"}, DaemonConfigFile, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.ConfigFile != "" || len(config.Conflicts) != 0 {
+		t.Errorf("unexpected config file %q and conflicts %v", config.ConfigFile, config.Conflicts)
+	}
+	if icc, err := config.Bool("icc", true); err != nil || icc {
+		t.Errorf("expected icc to be false, got %v (%v)", icc, err)
+	}
+
+	if _, err := newDaemonConfig(nil, DaemonConfigFile, []byte("{")); err == nil {
+		t.Errorf("expected an error for an invalid config file")
+	}
+}
+
+func TestTCPHosts(t *testing.T) {
+	options := &DaemonOptions{Hosts: []string{
+		"fd://",
+		"unix:///var/run/docker.sock",
+		"tcp://0.0.0.0:2376",
+		"tcp://127.0.0.1:2375",
+		"unix:///run/user/1000/docker.sock",
+	}}
+	expected := []string{"tcp://0.0.0.0:2376", "tcp://127.0.0.1:2375"}
+	if hosts := options.TCPHosts(); !reflect.DeepEqual(hosts, expected) {
+		t.Errorf("got %v, expected %v", hosts, expected)
+	}
+	if hosts := (&DaemonOptions{Hosts: []string{"fd://"}}).TCPHosts(); hosts != nil {
+		t.Errorf("expected no TCP hosts, got %v", hosts)
+	}
+}
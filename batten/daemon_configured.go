@@ -0,0 +1,300 @@
+package batten
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// systemdUnitDirs are searched for docker.service and its drop-ins, in
+// order of precedence.
+var systemdUnitDirs = []string{
+	"/etc/systemd/system",
+	"/run/systemd/system",
+	"/usr/lib/systemd/system",
+	"/lib/systemd/system",
+}
+
+// daemonEnvFiles are the distribution files that hold daemon options in
+// shell variables, and daemonEnvVars the variables that do.
+var (
+	daemonEnvFiles = []string{
+		"/etc/sysconfig/docker",
+		"/etc/sysconfig/docker-storage",
+		"/etc/sysconfig/docker-network",
+		"/etc/default/docker",
+	}
+	daemonEnvVars = []string{
+		"OPTIONS",
+		"DOCKER_OPTS",
+		"DOCKER_STORAGE_OPTIONS",
+		"DOCKER_NETWORK_OPTIONS",
+	}
+)
+
+// configuredDaemon is how the daemon is configured to be started, as
+// opposed to how it is running right now.
+type configuredDaemon struct {
+	Args []string
+
+	// the files the command line was put together from
+	Sources []string
+}
+
+// readConfiguredDaemon works out the command line the daemon would be
+// started with: the `ExecStart` of docker.service and its drop-ins, with
+// the variables of their environment files expanded, or the options of the
+// distribution's environment files for daemons not started by systemd. It
+// returns nil if the daemon is not configured in either way.
+func readConfiguredDaemon() (*configuredDaemon, error) {
+	unit, err := readDockerServiceUnit()
+	if err != nil || unit != nil {
+		return unit, err
+	}
+
+	configured := &configuredDaemon{Args: []string{"dockerd"}}
+	for _, filename := range daemonEnvFiles {
+		data, err := readHostFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		if data == nil {
+			continue
+		}
+		configured.Sources = append(configured.Sources, filename)
+		env := parseEnvFile(data)
+		for _, name := range daemonEnvVars {
+			configured.Args = append(configured.Args, splitWords(env[name])...)
+		}
+	}
+	if len(configured.Sources) == 0 {
+		return nil, nil
+	}
+	return configured, nil
+}
+
+// readDockerServiceUnit reads docker.service, overridden by its drop-ins.
+// Drop-ins are applied in the order of their names, a drop-in in a
+// directory of higher precedence hiding one of the same name elsewhere.
+func readDockerServiceUnit() (*configuredDaemon, error) {
+	var files []string
+	for _, dir := range systemdUnitDirs {
+		if PathExists(path.Join(dir, "docker.service")) {
+			files = append(files, path.Join(dir, "docker.service"))
+			break
+		}
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	dropIns := make(map[string]string)
+	var names []string
+	for _, dir := range systemdUnitDirs {
+		infos, err := readHostDir(path.Join(dir, "docker.service.d"))
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range infos {
+			name := info.Name()
+			if _, ok := dropIns[name]; ok || !strings.HasSuffix(name, ".conf") {
+				continue
+			}
+			dropIns[name] = path.Join(dir, "docker.service.d", name)
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		files = append(files, dropIns[name])
+	}
+
+	configured := &configuredDaemon{}
+	env := make(map[string]string)
+	var execStart []string
+	for _, filename := range files {
+		data, err := readHostFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		configured.Sources = append(configured.Sources, filename)
+
+		for _, setting := range parseUnitService(data) {
+			switch setting.key {
+			case "ExecStart":
+				// an empty ExecStart= clears the ones set before it
+				if setting.value == "" {
+					execStart = nil
+				} else {
+					execStart = splitWords(strings.TrimLeft(setting.value, "-@+!:"))
+				}
+			case "Environment":
+				for _, assignment := range splitWords(setting.value) {
+					if kv := strings.SplitN(assignment, "=", 2); len(kv) == 2 {
+						env[kv[0]] = kv[1]
+					}
+				}
+			case "EnvironmentFile":
+				filename := strings.TrimPrefix(setting.value, "-")
+				data, err := readHostFile(filename)
+				if err != nil {
+					return nil, err
+				}
+				if data == nil {
+					continue
+				}
+				configured.Sources = append(configured.Sources, filename)
+				for k, v := range parseEnvFile(data) {
+					env[k] = v
+				}
+			}
+		}
+	}
+
+	for _, word := range execStart {
+		configured.Args = append(configured.Args, expandUnitWord(word, env)...)
+	}
+	return configured, nil
+}
+
+// readHostDir lists a directory of the audited host, returning nothing if
+// it does not exist.
+func readHostDir(dirname string) ([]os.FileInfo, error) {
+	f, err := os.Open(hostPath(dirname))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Readdir(-1)
+}
+
+type unitSetting struct {
+	key   string
+	value string
+}
+
+// parseUnitService returns the settings of the [Service] section of a
+// systemd unit file, in order.
+func parseUnitService(data []byte) []unitSetting {
+	var settings []unitSetting
+	var section, line string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if line == "" && (strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";")) {
+			continue
+		}
+		// a trailing backslash continues the line
+		if strings.HasSuffix(text, "\\") {
+			line += strings.TrimSuffix(text, "\\") + " "
+			continue
+		}
+		line += text
+		text, line = line, ""
+
+		if strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]") {
+			section = text
+			continue
+		}
+		kv := strings.SplitN(text, "=", 2)
+		if section != "[Service]" || len(kv) != 2 {
+			continue
+		}
+		settings = append(settings, unitSetting{key: strings.TrimSpace(kv[0]), value: strings.TrimSpace(kv[1])})
+	}
+	return settings
+}
+
+// parseEnvFile reads the variables of a shell-style environment file,
+// such as /etc/sysconfig/docker.
+func parseEnvFile(data []byte) map[string]string {
+	env := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimPrefix(text, "export ")
+		kv := strings.SplitN(text, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		value := strings.TrimSpace(kv[1])
+		if words := splitWords(value); len(words) == 1 && len(value) > 1 && (value[0] == '"' || value[0] == '\'') {
+			value = words[0]
+		}
+		env[strings.TrimSpace(kv[0])] = value
+	}
+	return env
+}
+
+// splitWords splits `s` into words at whitespace, the way a shell would
+// for quoted strings and backslash escapes.
+func splitWords(s string) []string {
+	var words []string
+	var word []rune
+	var quote rune
+	inWord, escaped := false, false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			word = append(word, r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word = append(word, r)
+			}
+		case r == '"' || r == '\'':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, string(word))
+				word, inWord = nil, false
+			}
+		default:
+			word, inWord = append(word, r), true
+		}
+	}
+	if inWord {
+		words = append(words, string(word))
+	}
+	return words
+}
+
+// expandUnitWord expands the variables in a word of a unit's command
+// line. As systemd does, a word that is only `$VAR` becomes the words of
+// the variable's value, while `${VAR}` is substituted as is.
+func expandUnitWord(word string, env map[string]string) []string {
+	if strings.HasPrefix(word, "$") && isVariableName(word[1:]) {
+		return splitWords(env[word[1:]])
+	}
+	expanded := os.Expand(word, func(name string) string {
+		return env[name]
+	})
+	if expanded == "" {
+		return nil
+	}
+	return []string{expanded}
+}
+
+func isVariableName(s string) bool {
+	for i, r := range s {
+		if !(r == '_' || r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || i > 0 && r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,121 @@
+package batten
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// withHostFiles makes a host root holding `files` the audited host for
+// the duration of a test, and returns a function that undoes it.
+func withHostFiles(t *testing.T, files map[string]string) func() {
+	root, err := ioutil.TempDir("", "batten-host")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, contents := range files {
+		filename := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	saved := hostRoot
+	SetHostRoot(root)
+	return func() {
+		SetHostRoot(saved)
+		os.RemoveAll(root)
+	}
+}
+
+func TestReadConfiguredDaemonFromSystemd(t *testing.T) {
+	restore := withHostFiles(t, map[string]string{
+		"/usr/lib/systemd/system/docker.service": `[Unit]
+Description=Docker Application Container Engine
+
+[Service]
+EnvironmentFile=-/etc/sysconfig/docker
+EnvironmentFile=-/etc/sysconfig/docker-storage
+ExecStart=/usr/bin/dockerd-current \
+          --add-runtime docker-runc=/usr/libexec/docker/docker-runc-current \
+          $OPTIONS \
+          $DOCKER_STORAGE_OPTIONS
+`,
+		"/etc/sysconfig/docker": `# /etc/sysconfig/docker
+OPTIONS='--selinux-enabled --log-driver=journald --signature-verification=false'
+`,
+		// a drop-in replacing the command line, and one hidden by a
+		// drop-in of the same name in /etc
+		"/etc/systemd/system/docker.service.d/10-override.conf": `[Service]
+Environment="TLS=--tlsverify" "LABEL=zone a"
+ExecStart=
+ExecStart=/usr/bin/dockerd -H fd:// ${TLS} --label=${LABEL} $OPTIONS
+`,
+		"/usr/lib/systemd/system/docker.service.d/10-override.conf": `[Service]
+ExecStart=
+ExecStart=/usr/bin/dockerd --insecure-registry=10.0.0.1:5000
+`,
+	})
+	defer restore()
+
+	configured, err := readConfiguredDaemon()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"/usr/bin/dockerd", "-H", "fd://", "--tlsverify", "--label=zone a",
+		"--selinux-enabled", "--log-driver=journald", "--signature-verification=false",
+	}
+	if !reflect.DeepEqual(configured.Args, expected) {
+		t.Errorf("got %q, expected %q", configured.Args, expected)
+	}
+	sources := []string{
+		"/usr/lib/systemd/system/docker.service",
+		"/etc/sysconfig/docker",
+		"/etc/systemd/system/docker.service.d/10-override.conf",
+	}
+	if !reflect.DeepEqual(configured.Sources, sources) {
+		t.Errorf("got sources %q, expected %q", configured.Sources, sources)
+	}
+}
+
+func TestReadConfiguredDaemonFromDefaults(t *testing.T) {
+	restore := withHostFiles(t, map[string]string{
+		"/etc/default/docker": `# Use DOCKER_OPTS to modify the daemon startup options.
+export DOCKER_OPTS="--dns 8.8.8.8 --icc=false"
+`,
+	})
+	defer restore()
+
+	configured, err := readConfiguredDaemon()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"dockerd", "--dns", "8.8.8.8", "--icc=false"}
+	if !reflect.DeepEqual(configured.Args, expected) {
+		t.Errorf("got %q, expected %q", configured.Args, expected)
+	}
+}
+
+func TestDaemonDrift(t *testing.T) {
+	running := []string{"/usr/bin/dockerd", "-H", "fd://", "--insecure-registry=10.0.0.1:5000", "--icc=false"}
+	configured := []string{"/usr/bin/dockerd", "--icc=false", "-H", "fd://"}
+
+	findings := daemonDrift(running, configured)
+	expected := []Finding{{
+		Subject: "--insecure-registry",
+		Detail:  "running with '10.0.0.1:5000', configured with nothing",
+	}}
+	if !reflect.DeepEqual(findings, expected) {
+		t.Errorf("got %v, expected %v", findings, expected)
+	}
+
+	if findings := daemonDrift(configured, configured); len(findings) != 0 {
+		t.Errorf("expected no drift, got %v", findings)
+	}
+}
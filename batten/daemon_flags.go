@@ -0,0 +1,223 @@
+package batten
+
+import (
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	InvocationDockerd      = "dockerd"
+	InvocationDockerDaemon = "docker daemon"
+	InvocationDockerD      = "docker -d"
+)
+
+// daemonBoolFlags are the daemon's boolean flags. They may be given a
+// value with `--flag=value`, and older daemons also took `--flag value`.
+var daemonBoolFlags = map[string]bool{
+	"daemon":                  true,
+	"debug":                   true,
+	"disable-legacy-registry": true,
+	"experimental":            true,
+	"help":                    true,
+	"icc":                     true,
+	"init":                    true,
+	"ip-forward":              true,
+	"ip-masq":                 true,
+	"ip6tables":               true,
+	"iptables":                true,
+	"ipv6":                    true,
+	"live-restore":            true,
+	"no-new-privileges":       true,
+	"raw-logs":                true,
+	"rootless":                true,
+	"selinux-enabled":         true,
+	"signature-verification":  true,
+	"tls":                     true,
+	"tlsverify":               true,
+	"userland-proxy":          true,
+	"validate":                true,
+	"version":                 true,
+}
+
+// daemonShortFlags maps the daemon's one letter flags to their long names.
+var daemonShortFlags = map[string]string{
+	"b": "bridge",
+	"D": "debug",
+	"d": "daemon",
+	"e": "exec-driver",
+	"G": "group",
+	"g": "graph",
+	"H": "host",
+	"h": "help",
+	"l": "log-level",
+	"p": "pidfile",
+	"s": "storage-driver",
+	"v": "version",
+}
+
+// DaemonCommandLine is a parsed daemon command line.
+type DaemonCommandLine struct {
+	// how the daemon was invoked: `dockerd`, `docker daemon` or `docker -d`
+	Invocation string
+
+	// the values of every option, by long flag name. Boolean options have
+	// the value "true" or "false", and options given more than once have
+	// all their values, in order.
+	Options map[string][]string
+
+	// arguments that are not options
+	Args []string
+}
+
+// ParseDaemonCommandLine parses a daemon command line the way dockerd, or
+// the docker binary of the versions that ran the daemon, parses its flags.
+// Options are given with one or two dashes, their values after `=` or in
+// the next argument, and one letter flags may have their value attached
+// (`-Hfd://`) or be grouped (`-dD`).
+func ParseDaemonCommandLine(argv []string) *DaemonCommandLine {
+	cmdline := &DaemonCommandLine{
+		Invocation: InvocationDockerd,
+		Options:    make(map[string][]string),
+	}
+	if len(argv) == 0 {
+		return cmdline
+	}
+
+	binary := path.Base(argv[0])
+	legacy := binary == "docker"
+
+	args := argv[1:]
+	for i := 0; i < len(args); i++ {
+		arg := strings.TrimSpace(args[i])
+
+		switch {
+		case arg == "":
+			continue
+		case arg == "--":
+			cmdline.Args = append(cmdline.Args, args[i+1:]...)
+			return cmdline
+		case arg == "daemon" && legacy && cmdline.Invocation == InvocationDockerd && len(cmdline.Args) == 0:
+			cmdline.Invocation = InvocationDockerDaemon
+			continue
+		case !strings.HasPrefix(arg, "-") || arg == "-":
+			cmdline.Args = append(cmdline.Args, arg)
+			continue
+		}
+
+		var name, value string
+		hasValue := false
+
+		if strings.HasPrefix(arg, "--") {
+			name = arg[2:]
+		} else {
+			name = arg[1:]
+			if short, ok := daemonShortFlags[name[:1]]; ok && !isLongFlag(name) {
+				// a one letter flag, with its value attached or grouped
+				// with other boolean one letter flags
+				rest := name[1:]
+				name = short
+				if strings.HasPrefix(rest, "=") {
+					value, hasValue = rest[1:], true
+				} else if rest != "" && daemonBoolFlags[short] && allShortBoolFlags(rest) {
+					for _, r := range rest {
+						cmdline.setBool(daemonShortFlags[string(r)], legacy)
+					}
+				} else if rest != "" {
+					value, hasValue = rest, true
+				}
+			}
+		}
+		if j := strings.Index(name, "="); j >= 0 && !hasValue {
+			name, value, hasValue = name[:j], name[j+1:], true
+		}
+
+		if daemonBoolFlags[name] {
+			if !hasValue && i+1 < len(args) && isBoolValue(args[i+1]) {
+				i++
+				value, hasValue = strings.TrimSpace(args[i]), true
+			}
+			if !hasValue {
+				value = "true"
+			}
+			if b, err := strconv.ParseBool(value); err == nil {
+				value = strconv.FormatBool(b)
+			}
+		} else if !hasValue && i+1 < len(args) && (!strings.HasPrefix(args[i+1], "-") || args[i+1] == "-") {
+			i++
+			value = strings.TrimSpace(args[i])
+		} else if !hasValue {
+			// an unknown flag without a value is taken to be boolean
+			value = "true"
+		}
+
+		if name == "daemon" {
+			if value == "true" {
+				cmdline.setBool(name, legacy)
+			}
+			continue
+		}
+		cmdline.set(name, value)
+	}
+	return cmdline
+}
+
+func (c *DaemonCommandLine) set(name string, value string) {
+	c.Options[name] = append(c.Options[name], value)
+}
+
+// setBool sets a boolean flag given without a value. `-d` is how the
+// docker binary was told to run the daemon, not an option of the daemon.
+func (c *DaemonCommandLine) setBool(name string, legacy bool) {
+	if name == "daemon" {
+		if legacy {
+			c.Invocation = InvocationDockerD
+		}
+		return
+	}
+	c.set(name, "true")
+}
+
+// isLongFlag returns true if `name`, given after a single dash, is a long
+// flag, as the docker binary used to accept (`-icc=false`).
+func isLongFlag(name string) bool {
+	if j := strings.Index(name, "="); j >= 0 {
+		name = name[:j]
+	}
+	return len(name) > 1 && (daemonBoolFlags[name] || isDaemonValueFlag(name))
+}
+
+// isDaemonValueFlag returns true for the daemon's long flags that take a
+// value and clash with a one letter flag when given after a single dash.
+func isDaemonValueFlag(name string) bool {
+	for _, flag := range daemonJSONKeys {
+		if flag == name {
+			return true
+		}
+	}
+	switch name {
+	case "log-level", "tlscacert", "tlscert", "tlskey", "bridge", "bip", "graph", "group", "pidfile",
+		"storage-driver", "exec-driver", "log-driver", "dns", "fixed-cidr", "mtu", "ip", "config-file":
+		return true
+	}
+	return false
+}
+
+func allShortBoolFlags(flags string) bool {
+	for _, r := range flags {
+		if !daemonBoolFlags[daemonShortFlags[string(r)]] {
+			return false
+		}
+	}
+	return true
+}
+
+func isBoolValue(arg string) bool {
+	_, err := strconv.ParseBool(strings.TrimSpace(arg))
+	return err == nil
+}
+
+// parseDaemonArgs collects the options given on the daemon's command line.
+func parseDaemonArgs(args []string) map[string][]string {
+	return ParseDaemonCommandLine(args).Options
+}
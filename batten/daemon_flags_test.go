@@ -0,0 +1,154 @@
+package batten
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDaemonCommandLine(t *testing.T) {
+	tests := []struct {
+		cmdline    string
+		invocation string
+		options    map[string][]string
+	}{
+		{
+			// Debian and Ubuntu systemd unit
+			cmdline:    "/usr/bin/dockerd -H fd:// --containerd=/run/containerd/containerd.sock",
+			invocation: InvocationDockerd,
+			options: map[string][]string{
+				"host":       {"fd://"},
+				"containerd": {"/run/containerd/containerd.sock"},
+			},
+		},
+		{
+			// Docker 1.6 era, with boolean values in the next argument
+			cmdline:    "/usr/bin/docker -d -H unix:///var/run/docker.sock --icc false --iptables=0",
+			invocation: InvocationDockerD,
+			options: map[string][]string{
+				"host":     {"unix:///var/run/docker.sock"},
+				"icc":      {"false"},
+				"iptables": {"false"},
+			},
+		},
+		{
+			cmdline:    "docker daemon --tlsverify=true --tlscacert=/etc/docker/ca.pem --tlscert /etc/docker/cert.pem --tlskey=/etc/docker/key.pem -H=0.0.0.0:2376",
+			invocation: InvocationDockerDaemon,
+			options: map[string][]string{
+				"tlsverify": {"true"},
+				"tlscacert": {"/etc/docker/ca.pem"},
+				"tlscert":   {"/etc/docker/cert.pem"},
+				"tlskey":    {"/etc/docker/key.pem"},
+				"host":      {"0.0.0.0:2376"},
+			},
+		},
+		{
+			// RHEL and CentOS docker package
+			cmdline: "/usr/bin/dockerd-current --add-runtime docker-runc=/usr/libexec/docker/docker-runc-current " +
+				"--default-runtime=docker-runc --exec-opt native.cgroupdriver=systemd " +
+				"--seccomp-profile=/etc/docker/seccomp.json --selinux-enabled --log-driver=journald " +
+				"--signature-verification=false --storage-driver overlay2",
+			invocation: InvocationDockerd,
+			options: map[string][]string{
+				"add-runtime":            {"docker-runc=/usr/libexec/docker/docker-runc-current"},
+				"default-runtime":        {"docker-runc"},
+				"exec-opt":               {"native.cgroupdriver=systemd"},
+				"seccomp-profile":        {"/etc/docker/seccomp.json"},
+				"selinux-enabled":        {"true"},
+				"log-driver":             {"journald"},
+				"signature-verification": {"false"},
+				"storage-driver":         {"overlay2"},
+			},
+		},
+		{
+			// one letter flags with attached values, and repeated list flags
+			cmdline:    "dockerd -D -l=warn -Htcp://127.0.0.1:2375 --host unix:///var/run/docker.sock --insecure-registry a:5000 --insecure-registry=b:5000",
+			invocation: InvocationDockerd,
+			options: map[string][]string{
+				"debug":             {"true"},
+				"log-level":         {"warn"},
+				"host":              {"tcp://127.0.0.1:2375", "unix:///var/run/docker.sock"},
+				"insecure-registry": {"a:5000", "b:5000"},
+			},
+		},
+		{
+			// long flags after a single dash, and grouped one letter flags
+			cmdline:    "docker -dD -icc=false -tlsverify -log-level debug -g /data/docker",
+			invocation: InvocationDockerD,
+			options: map[string][]string{
+				"debug":     {"true"},
+				"icc":       {"false"},
+				"tlsverify": {"true"},
+				"log-level": {"debug"},
+				"graph":     {"/data/docker"},
+			},
+		},
+		{
+			// a boolean flag is not given the next flag as its value
+			cmdline:    "dockerd --tlsverify --icc=TRUE --userland-proxy=f --live-restore -- --icc=false",
+			invocation: InvocationDockerd,
+			options: map[string][]string{
+				"tlsverify":      {"true"},
+				"icc":            {"true"},
+				"userland-proxy": {"false"},
+				"live-restore":   {"true"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		cmdline := ParseDaemonCommandLine(strings.Fields(test.cmdline))
+		if cmdline.Invocation != test.invocation {
+			t.Errorf("%s: got invocation %q, expected %q", test.cmdline, cmdline.Invocation, test.invocation)
+		}
+		if !reflect.DeepEqual(cmdline.Options, test.options) {
+			t.Errorf("%s:\ngot      %v\nexpected %v", test.cmdline, cmdline.Options, test.options)
+		}
+	}
+}
+
+func TestDaemonOptions(t *testing.T) {
+	tests := []struct {
+		cmdline string
+		file    string
+		check   func(*DaemonOptions) bool
+	}{
+		{"dockerd", "", func(o *DaemonOptions) bool { return o.ICC && o.IPTables && o.LogLevel == "info" && !o.TLSVerify }},
+		{"dockerd --icc false", "", func(o *DaemonOptions) bool { return !o.ICC }},
+		{"dockerd", `{"icc": false, "iptables": false}`, func(o *DaemonOptions) bool { return !o.ICC && !o.IPTables }},
+		{"dockerd -H tcp://0.0.0.0:2376", `{"tlsverify": true, "tlscert": "/c.pem", "tlskey": "/k.pem"}`, func(o *DaemonOptions) bool {
+			return o.TLSVerify && o.TLSCert == "/c.pem" && o.TLSKey == "/k.pem" && reflect.DeepEqual(o.Hosts, []string{"tcp://0.0.0.0:2376"})
+		}},
+		{"dockerd", "", func(o *DaemonOptions) bool {
+			return !o.LiveRestore && o.UserlandProxy && !o.NoNewPrivileges && !o.Experimental && o.CgroupDriver == "cgroupfs"
+		}},
+		{"dockerd --live-restore --userland-proxy=false --exec-opt native.cgroupdriver=systemd", `{"no-new-privileges": true, "seccomp-profile": "unconfined"}`, func(o *DaemonOptions) bool {
+			return o.LiveRestore && !o.UserlandProxy && o.NoNewPrivileges && o.SeccompProfile == "unconfined" && o.CgroupDriver == "systemd"
+		}},
+		{"dockerd --registry-mirror=https://m", `{"insecure-registries": ["r:5000"]}`, func(o *DaemonOptions) bool {
+			return reflect.DeepEqual(o.RegistryMirrors, []string{"https://m"}) && reflect.DeepEqual(o.InsecureRegistries, []string{"r:5000"})
+		}},
+	}
+
+	for _, test := range tests {
+		var file []byte
+		if test.file != "" {
+			file = []byte(test.file)
+		}
+		config, err := newDaemonConfig(strings.Fields(test.cmdline), DaemonConfigFile, file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		options, err := config.Options()
+		if err != nil {
+			t.Errorf("%s %s: unexpected error: %v", test.cmdline, test.file, err)
+		} else if !test.check(options) {
+			t.Errorf("%s %s: unexpected options %+v", test.cmdline, test.file, options)
+		}
+	}
+
+	config, _ := newDaemonConfig([]string{"dockerd"}, DaemonConfigFile, []byte(`{"icc": "maybe"}`))
+	if _, err := config.Options(); err == nil {
+		t.Errorf("expected an error for an invalid boolean")
+	}
+}
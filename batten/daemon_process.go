@@ -0,0 +1,373 @@
+package batten
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	DefaultDataRoot         = "/var/lib/docker"
+	DefaultContainerdConfig = "/etc/containerd/config.toml"
+	DefaultContainerdSocket = "/run/containerd/containerd.sock"
+)
+
+// procRoot is where the processes of the audited host are found. A scan
+// container shares the host's pid namespace, so it is always our own.
+var procRoot = "/proc"
+
+// auditedDaemon is the daemon the checks audit while they are run once
+// for every daemon found, and nil otherwise.
+var auditedDaemon *DaemonProcess
+
+// DaemonProcess is a Docker daemon running on the audited host.
+type DaemonProcess struct {
+	Pid        int
+	Args       []string
+	Invocation string
+
+	PidFile    string
+	DataRoot   string
+	ConfigFile string
+
+	// a rootless daemon, run by rootlesskit as an unprivileged user
+	Rootless bool
+
+	// where the daemon's own files are found, when it runs in another
+	// mount namespace than the host, e.g. Docker in Docker
+	Root string
+
+	environ map[string]string
+}
+
+// String describes the daemon well enough to tell it from the others.
+func (d *DaemonProcess) String() string {
+	var details []string
+	details = append(details, fmt.Sprintf("pid %d", d.Pid))
+	if d.Rootless {
+		details = append(details, "rootless")
+	} else if d.Root != "" {
+		details = append(details, "in a container")
+	}
+	if d.DataRoot != DefaultDataRoot {
+		details = append(details, "data root "+d.DataRoot)
+	}
+	return fmt.Sprintf("%s (%s)", d.Invocation, strings.Join(details, ", "))
+}
+
+// path returns where the daemon's `filename` can be found from here.
+func (d *DaemonProcess) path(filename string) string {
+	if d.Root != "" {
+		return path.Join(d.Root, filename)
+	}
+	return hostPath(filename)
+}
+
+// readFile reads one of the daemon's files, returning nil if it does not
+// exist.
+func (d *DaemonProcess) readFile(filename string) ([]byte, error) {
+	data, err := ioutil.ReadFile(d.path(filename))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// ContainerdProcess is a containerd running on the audited host, either
+// on its own or started by the Docker daemon.
+type ContainerdProcess struct {
+	Pid  int
+	Args []string
+
+	// the configuration file, and whether it exists; containerd runs with
+	// its defaults without one
+	ConfigFile   string
+	ConfigExists bool
+
+	// the socket of its GRPC API
+	Address string
+}
+
+// FindDaemons finds the Docker daemons running on the audited host, in
+// order of their pid. There can be more than one: a rootless daemon, a
+// daemon running in a container, or daemons with their own data roots.
+func FindDaemons() ([]*DaemonProcess, error) {
+	pids, err := listPids()
+	if err != nil {
+		return nil, err
+	}
+
+	var daemons []*DaemonProcess
+	for _, pid := range pids {
+		argv := readCmdline(pid)
+		if !isDockerDaemon(argv) {
+			continue
+		}
+		daemons = append(daemons, newDaemonProcess(pid, argv))
+	}
+	return daemons, nil
+}
+
+// FindContainerd finds the containerd processes of the audited host, in
+// order of their pid. Their shims are not included.
+func FindContainerd() ([]*ContainerdProcess, error) {
+	pids, err := listPids()
+	if err != nil {
+		return nil, err
+	}
+
+	var found []*ContainerdProcess
+	for _, pid := range pids {
+		argv := readCmdline(pid)
+		if len(argv) == 0 {
+			continue
+		}
+		switch path.Base(argv[0]) {
+		case "containerd", "docker-containerd", "docker-containerd-current":
+		default:
+			continue
+		}
+		found = append(found, newContainerdProcess(pid, argv))
+	}
+	return found, nil
+}
+
+func listPids() ([]int, error) {
+	infos, err := ioutil.ReadDir(procRoot)
+	if err != nil {
+		return nil, err
+	}
+	var pids []int
+	for _, info := range infos {
+		if pid, err := strconv.Atoi(info.Name()); err == nil && info.IsDir() {
+			pids = append(pids, pid)
+		}
+	}
+	sort.Ints(pids)
+	return pids, nil
+}
+
+// readCmdline reads the command line of a process, which is empty for
+// kernel threads and processes that are gone.
+func readCmdline(pid int) []string {
+	data, err := ioutil.ReadFile(path.Join(procRoot, strconv.Itoa(pid), "cmdline"))
+	if err != nil {
+		return nil
+	}
+	var argv []string
+	for _, arg := range strings.Split(string(data), "\x00") {
+		if arg != "" {
+			argv = append(argv, arg)
+		}
+	}
+	return argv
+}
+
+// readEnviron reads the environment of a process, which can only be read
+// by its owner and root.
+func readEnviron(pid int) map[string]string {
+	environ := make(map[string]string)
+	data, err := ioutil.ReadFile(path.Join(procRoot, strconv.Itoa(pid), "environ"))
+	if err != nil {
+		return environ
+	}
+	for _, item := range strings.Split(string(data), "\x00") {
+		if kv := strings.SplitN(item, "=", 2); len(kv) == 2 {
+			environ[kv[0]] = kv[1]
+		}
+	}
+	return environ
+}
+
+// isDockerDaemon returns true for the command line of a Docker daemon:
+// `dockerd`, including distribution builds such as `dockerd-current`, or
+// the docker binary run as `docker daemon` or `docker -d`.
+func isDockerDaemon(argv []string) bool {
+	if len(argv) == 0 {
+		return false
+	}
+	binary := path.Base(argv[0])
+	switch {
+	case strings.HasPrefix(binary, "dockerd"):
+		// but not the scripts that set up a rootless daemon
+		return !strings.HasSuffix(binary, ".sh")
+	case binary == "docker":
+		return ParseDaemonCommandLine(argv).Invocation != InvocationDockerd
+	}
+	return false
+}
+
+// newDaemonProcess works out where a daemon keeps its pid, data and
+// configuration: from its command line, then its configuration file,
+// then the defaults of a rootful or rootless daemon.
+func newDaemonProcess(pid int, argv []string) *DaemonProcess {
+	cmdline := ParseDaemonCommandLine(argv)
+	d := &DaemonProcess{
+		Pid:        pid,
+		Args:       argv,
+		Invocation: cmdline.Invocation,
+		PidFile:    DockerPidFile,
+		DataRoot:   DefaultDataRoot,
+		ConfigFile: DaemonConfigFile,
+	}
+
+	prefix := path.Join(procRoot, strconv.Itoa(pid))
+	d.environ = readEnviron(pid)
+	d.Rootless = d.environ["ROOTLESSKIT_STATE_DIR"] != "" || lastValue(cmdline.Options["rootless"]) == "true"
+	if ns, err := os.Readlink(path.Join(prefix, "ns", "mnt")); err == nil {
+		if hostNs, err := os.Readlink(path.Join(procRoot, "1", "ns", "mnt")); err == nil && ns != hostNs {
+			d.Root = path.Join(prefix, "root")
+		}
+	}
+
+	if d.Rootless {
+		// rootless daemons keep their files in the user's directories
+		home := d.environ["HOME"]
+		d.PidFile = path.Join(d.environ["XDG_RUNTIME_DIR"], "docker.pid")
+		d.DataRoot = path.Join(envOr(d.environ, "XDG_DATA_HOME", path.Join(home, ".local/share")), "docker")
+		d.ConfigFile = path.Join(envOr(d.environ, "XDG_CONFIG_HOME", path.Join(home, ".config")), "docker/daemon.json")
+	}
+	if configFile := lastValue(cmdline.Options["config-file"]); configFile != "" {
+		d.ConfigFile = configFile
+	}
+
+	// an invalid configuration file is reported by the checks, which
+	// read it again
+	file, _ := d.readFile(d.ConfigFile)
+	config, err := newDaemonConfig(argv, d.ConfigFile, file)
+	if err != nil {
+		config, _ = newDaemonConfig(argv, d.ConfigFile, nil)
+	}
+	d.PidFile = config.Value("pidfile", d.PidFile)
+	d.DataRoot = config.Value("data-root", config.Value("graph", d.DataRoot))
+	return d
+}
+
+func newContainerdProcess(pid int, argv []string) *ContainerdProcess {
+	c := &ContainerdProcess{
+		Pid:        pid,
+		Args:       argv,
+		ConfigFile: DefaultContainerdConfig,
+		Address:    DefaultContainerdSocket,
+	}
+
+	var address string
+	for i := 1; i < len(argv); i++ {
+		name, value := argv[i], ""
+		if j := strings.Index(name, "="); j >= 0 {
+			name, value = name[:j], name[j+1:]
+		} else if i+1 < len(argv) {
+			value = argv[i+1]
+		}
+		switch strings.TrimLeft(name, "-") {
+		case "config", "c":
+			c.ConfigFile = value
+		case "address", "a":
+			address = value
+		}
+	}
+
+	data, err := ioutil.ReadFile(hostPath(c.ConfigFile))
+	c.ConfigExists = err == nil
+	if a := containerdGRPCAddress(data); a != "" {
+		c.Address = a
+	}
+	if address != "" {
+		c.Address = address
+	}
+	return c
+}
+
+// containerdGRPCAddress reads the `address` of the [grpc] section of a
+// containerd configuration file.
+func containerdGRPCAddress(data []byte) string {
+	var section string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(text, "[") {
+			section = text
+			continue
+		}
+		kv := strings.SplitN(text, "=", 2)
+		if section == "[grpc]" && len(kv) == 2 && strings.TrimSpace(kv[0]) == "address" {
+			return strings.Trim(strings.TrimSpace(kv[1]), `"'`)
+		}
+	}
+	return ""
+}
+
+func envOr(env map[string]string, name string, def string) string {
+	if value := env[name]; value != "" {
+		return value
+	}
+	return def
+}
+
+func lastValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
+
+// readPidFile reads the pid held in `filename`, returning 0 if it does
+// not exist.
+func readPidFile(filename string) (int, error) {
+	data, err := readHostFile(filename)
+	if err != nil || data == nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("%s does not hold a pid: %q", filename, strings.TrimSpace(string(data)))
+	}
+	return pid, nil
+}
+
+// findDaemon finds the daemon to audit. While the checks are run for
+// every daemon, it is the daemon being audited. Otherwise it is the daemon
+// whose pid is in `dockerPidFile`, or the audited endpoint's pid file,
+// then the daemon configured to use that pid file, and then the first
+// daemon found. A pid file that cannot be read, or whose pid is not a
+// daemon's, is stale and ignored. It returns nil if no daemon is running.
+func findDaemon(dockerPidFile string) (*DaemonProcess, error) {
+	if dockerPidFile == "" && auditedDaemon != nil {
+		return auditedDaemon, nil
+	}
+	if dockerPidFile == "" {
+		dockerPidFile = daemonPidFile
+	}
+
+	daemons, err := FindDaemons()
+	if err != nil {
+		return nil, err
+	}
+	pid, err := readPidFile(dockerPidFile)
+	if err != nil {
+		logrus.Debugf("Ignoring pid file %s: %v", dockerPidFile, err)
+	}
+
+	for _, d := range daemons {
+		if pid > 0 && d.Pid == pid {
+			return d, nil
+		}
+	}
+	for _, d := range daemons {
+		if d.PidFile == dockerPidFile {
+			return d, nil
+		}
+	}
+	if len(daemons) > 0 {
+		return daemons[0], nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,169 @@
+package batten
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type fakeProcess struct {
+	cmdline string
+	environ []string
+}
+
+// withProcesses makes the audited host run `processes`, by pid, for the
+// duration of a test, and returns a function that undoes it.
+func withProcesses(t *testing.T, processes map[int]fakeProcess) func() {
+	root, err := ioutil.TempDir("", "batten-proc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pid, p := range processes {
+		dir := path.Join(root, strconv.Itoa(pid))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		cmdline := strings.Join(strings.Fields(p.cmdline), "\x00")
+		if err := ioutil.WriteFile(path.Join(dir, "cmdline"), []byte(cmdline), 0644); err != nil {
+			t.Fatal(err)
+		}
+		environ := strings.Join(p.environ, "\x00")
+		if err := ioutil.WriteFile(path.Join(dir, "environ"), []byte(environ), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	saved := procRoot
+	procRoot = root
+	return func() {
+		procRoot = saved
+		os.RemoveAll(root)
+	}
+}
+
+var hostProcesses = map[int]fakeProcess{
+	1:   {cmdline: "/sbin/init"},
+	2:   {cmdline: ""},
+	700: {cmdline: "/usr/bin/containerd"},
+	701: {cmdline: "/usr/bin/containerd-shim-runc-v2 -namespace moby -id 1234"},
+	812: {cmdline: "/usr/bin/dockerd -H fd:// --containerd=/run/containerd/containerd.sock"},
+	900: {cmdline: "docker ps -a"},
+	1200: {
+		cmdline: "dockerd",
+		environ: []string{"HOME=/home/ci", "XDG_RUNTIME_DIR=/run/user/1000", "ROOTLESSKIT_STATE_DIR=/tmp/rootlesskit"},
+	},
+	1250: {cmdline: "/bin/sh /usr/bin/dockerd-rootless.sh"},
+	1300: {cmdline: "/usr/bin/docker daemon --data-root /var/lib/docker-ci -p /run/docker-ci.pid"},
+}
+
+func TestFindDaemons(t *testing.T) {
+	defer withProcesses(t, hostProcesses)()
+	defer withHostFiles(t, map[string]string{
+		"/etc/docker/daemon.json": `{"pidfile": "/run/docker/dockerd.pid"}`,
+	})()
+
+	daemons, err := FindDaemons()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found []string
+	for _, d := range daemons {
+		found = append(found, d.String()+" "+d.PidFile+" "+d.ConfigFile)
+	}
+	expected := []string{
+		"dockerd (pid 812) /run/docker/dockerd.pid /etc/docker/daemon.json",
+		"dockerd (pid 1200, rootless, data root /home/ci/.local/share/docker) /run/user/1000/docker.pid /home/ci/.config/docker/daemon.json",
+		"docker daemon (pid 1300, data root /var/lib/docker-ci) /run/docker-ci.pid /etc/docker/daemon.json",
+	}
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("got\n%s\nexpected\n%s", strings.Join(found, "\n"), strings.Join(expected, "\n"))
+	}
+
+	containerd, err := FindContainerd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(containerd) != 1 || containerd[0].Pid != 700 || containerd[0].Address != DefaultContainerdSocket {
+		t.Errorf("unexpected containerd %+v", containerd)
+	}
+}
+
+func TestFindDaemon(t *testing.T) {
+	defer withProcesses(t, hostProcesses)()
+
+	tests := []struct {
+		pidfile  string
+		contents string
+		pid      int
+		err      bool
+	}{
+		// the pid file holds the pid, with a newline
+		{"/var/run/docker.pid", "1300\n", 1300, false},
+		// no pid file: the daemon configured with it
+		{"/run/docker-ci.pid", "", 1300, false},
+		// no pid file, and no daemon configured with it: the first daemon
+		{"/var/run/docker.pid", "", 812, false},
+		// a stale pid file, whose pid is now another process's
+		{"/var/run/docker.pid", "900\n", 812, false},
+		{"/var/run/docker.pid", "1\n", 812, false},
+		// a corrupt pid file is ignored too
+		{"/var/run/docker.pid", "docker\n", 812, false},
+	}
+
+	for _, test := range tests {
+		files := map[string]string{}
+		if test.contents != "" {
+			files[test.pidfile] = test.contents
+		}
+		restore := withHostFiles(t, files)
+
+		daemon, err := findDaemon(test.pidfile)
+		if test.err && err == nil {
+			t.Errorf("%s %q: expected an error", test.pidfile, test.contents)
+		} else if !test.err && err != nil {
+			t.Errorf("%s %q: unexpected error: %v", test.pidfile, test.contents, err)
+		} else if !test.err && (daemon == nil || daemon.Pid != test.pid) {
+			t.Errorf("%s %q: got %v, expected pid %d", test.pidfile, test.contents, daemon, test.pid)
+		}
+		restore()
+	}
+}
+
+type fakeDaemonCheck struct {
+	*CheckDefinitionImpl
+	DaemonCheck
+	failing map[int]bool
+}
+
+func (c *fakeDaemonCheck) GetCheckDefinition() CheckDefinition {
+	return c
+}
+
+func (c *fakeDaemonCheck) AuditCheck() (bool, error) {
+	daemon, err := findDaemon("")
+	if err != nil {
+		return false, err
+	}
+	return !c.failing[daemon.Pid], nil
+}
+
+func TestRunCheckForEachDaemon(t *testing.T) {
+	defer withProcesses(t, hostProcesses)()
+	defer withHostFiles(t, nil)()
+
+	check := &fakeDaemonCheck{CheckDefinitionImpl: &CheckDefinitionImpl{}, failing: map[int]bool{1300: true}}
+	results := RunCheck(check)
+	expected := []Finding{{Subject: "docker daemon (pid 1300, data root /var/lib/docker-ci)"}}
+	if results.Success || results.Error != nil || !reflect.DeepEqual(results.Findings, expected) {
+		t.Errorf("got %+v, expected findings %v", results, expected)
+	}
+
+	check.failing = nil
+	if results := RunCheck(check); !results.Success || len(results.Findings) != 0 {
+		t.Errorf("expected success, got %+v", results)
+	}
+}
@@ -0,0 +1,240 @@
+package batten
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// apiEndpoint is the endpoint of the Docker API the checks talk to, when
+// it was chosen with UseDockerEndpoint.
+var apiEndpoint *Endpoint
+
+// containerInspect is what the checks read of `docker inspect` for a
+// container. The vendored client predates options such as user namespaces,
+// so containers are inspected with getDockerAPI instead.
+type containerInspect struct {
+	ID           string `json:"Id"`
+	Name         string
+	RestartCount int
+
+	Config struct {
+		Image       string
+		Healthcheck *struct {
+			Test []string
+		}
+	}
+
+	State struct {
+		Running bool
+		Pid     int
+		Health  *struct {
+			Status        string
+			FailingStreak int
+		}
+	}
+
+	Mounts []mountPoint
+
+	NetworkSettings struct {
+		Ports map[string][]portBinding
+	}
+
+	HostConfig struct {
+		Devices []struct {
+			PathOnHost        string
+			PathInContainer   string
+			CgroupPermissions string
+		}
+		DeviceCgroupRules []string
+		PortBindings      map[string][]portBinding
+		PublishAllPorts   bool
+		ReadonlyRootfs    bool
+		RestartPolicy     struct {
+			Name              string
+			MaximumRetryCount int
+		}
+		Tmpfs  map[string]string
+		Mounts []struct {
+			Type         string
+			Target       string
+			TmpfsOptions *struct {
+				Options [][]string
+			}
+		}
+		Memory         int64
+		OomKillDisable bool
+		CPUShares      int64 `json:"CpuShares"`
+		CPUQuota       int64 `json:"CpuQuota"`
+		NanoCPUs       int64 `json:"NanoCpus"`
+		PidsLimit      int64
+		Privileged     bool
+		SecurityOpt    []string
+		CapAdd         []string
+		CapDrop        []string
+		NetworkMode    string
+		PidMode        string
+		IpcMode        string
+		UTSMode        string
+		UsernsMode     string
+		CgroupnsMode   string
+		LogConfig      struct {
+			Type   string
+			Config map[string]string
+		}
+	}
+}
+
+// mountPoint is a mount of a container, as `docker inspect` lists it.
+type mountPoint struct {
+	Type        string
+	Source      string
+	Destination string
+	Mode        string
+	RW          bool
+	Propagation string
+}
+
+func (m mountPoint) String() string {
+	access := "read-only"
+	if m.RW {
+		access = "writable"
+	}
+	return fmt.Sprintf("%s on %s (%s, mode '%s', propagation '%s')", m.Source, m.Destination, access, m.Mode, m.Propagation)
+}
+
+// portBinding is where a port of a container is published on the host.
+type portBinding struct {
+	HostIP   string `json:"HostIp"`
+	HostPort string
+}
+
+// ContainerName returns the name of the container without its leading
+// slash, as `docker ps` shows it.
+func (c *containerInspect) ContainerName() string {
+	return strings.TrimPrefix(c.Name, "/")
+}
+
+// Subject names the container, and the image it runs, in findings.
+func (c *containerInspect) Subject() string {
+	return fmt.Sprintf("%s (%s)", c.ContainerName(), c.Config.Image)
+}
+
+// SecurityOption returns the value the container was given the security
+// option `name` with, as `--security-opt name=value` or the older
+// `name:value`. Options given without a value, like no-new-privileges,
+// are "true".
+func (c *containerInspect) SecurityOption(name string) (string, bool) {
+	value, found := "", false
+	for _, opt := range c.HostConfig.SecurityOpt {
+		if opt == name {
+			value, found = "true", true
+		} else if strings.HasPrefix(opt, name+"=") || strings.HasPrefix(opt, name+":") {
+			value, found = opt[len(name)+1:], true
+		}
+	}
+	return value, found
+}
+
+// getDockerAPI decodes the response to a GET of `path` on the Docker API
+// into `v`.
+func getDockerAPI(path string, v interface{}) error {
+	endpoint := apiEndpoint
+	if endpoint == nil {
+		if dockerClient != nil {
+			return errors.New("the Docker API endpoint is not known")
+		}
+		endpoint = localEndpoint()
+	}
+
+	client, base, err := endpoint.httpClient()
+	if err != nil {
+		return err
+	}
+	resp, err := client.Get(base + path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("GET %s: %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// httpClient returns a client for the endpoint, and the URL its requests
+// are made relative to.
+func (e *Endpoint) httpClient() (*http.Client, string, error) {
+	if socket := e.SocketPath(); socket != "" {
+		transport := &http.Transport{
+			Dial: func(network, addr string) (net.Conn, error) {
+				return net.Dial("unix", socket)
+			},
+		}
+		return &http.Client{Transport: transport, Timeout: time.Minute}, "http://docker", nil
+	}
+
+	u, err := url.Parse(e.Host)
+	if err != nil {
+		return nil, "", err
+	}
+	switch u.Scheme {
+	case "tcp", "http", "https":
+	default:
+		return nil, "", fmt.Errorf("unsupported docker host '%s'", e.Host)
+	}
+	u.Scheme = "http"
+	if e.usesTLS() {
+		u.Scheme = "https"
+	}
+	transport, err := e.transport()
+	if err != nil {
+		return nil, "", err
+	}
+	return &http.Client{Transport: transport, Timeout: time.Minute}, strings.TrimRight(u.String(), "/"), nil
+}
+
+// inspectRunningContainers inspects every running container.
+func inspectRunningContainers() ([]*containerInspect, error) {
+	var list []struct {
+		ID string `json:"Id"`
+	}
+	if err := getDockerAPI("/containers/json", &list); err != nil {
+		return nil, err
+	}
+
+	var containers []*containerInspect
+	for _, c := range list {
+		container := &containerInspect{}
+		if err := getDockerAPI("/containers/"+c.ID+"/json", container); err != nil {
+			return nil, err
+		}
+		containers = append(containers, container)
+	}
+	return containers, nil
+}
+
+// auditRunningContainers returns the findings of `audit` for every running
+// container, each given as its details about the container.
+func auditRunningContainers(audit func(c *containerInspect) []string) (bool, []Finding, error) {
+	containers, err := inspectRunningContainers()
+	if err != nil {
+		return false, nil, err
+	}
+
+	var findings []Finding
+	for _, c := range containers {
+		for _, detail := range audit(c) {
+			findings = append(findings, Finding{Subject: c.Subject(), Detail: detail})
+		}
+	}
+	return len(findings) == 0, findings, nil
+}
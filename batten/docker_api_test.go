@@ -0,0 +1,136 @@
+package batten
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	docker "github.com/fsouza/go-dockerclient"
+	dtesting "github.com/fsouza/go-dockerclient/testing"
+)
+
+// fakeDocker is go-dockerclient's fake Docker daemon, with the endpoints
+// the checks read that it does not serve itself.
+type fakeDocker struct {
+	*dtesting.DockerServer
+	client *docker.Client
+
+	// the server hands over the containers it creates and starts here
+	containers chan *docker.Container
+	created    []*docker.Container
+}
+
+func newFakeDocker(t *testing.T) *fakeDocker {
+	containers := make(chan *docker.Container, 16)
+	server, err := dtesting.NewServer("127.0.0.1:0", containers, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.CustomHandler("^/version$", jsonHandler(map[string]string{"Version": "1.6.0", "ApiVersion": "1.18"}))
+	server.CustomHandler("^/info$", jsonHandler(map[string]string{"Driver": "overlay", "ExecutionDriver": "native-0.2"}))
+
+	client, err := docker.NewClient(strings.TrimSuffix(server.URL(), "/"))
+	if err != nil {
+		server.Stop()
+		t.Fatal(err)
+	}
+	f := &fakeDocker{DockerServer: server, client: client, containers: containers}
+	server.CustomHandler("^/containers/json$", http.HandlerFunc(f.listContainers))
+	return f
+}
+
+// listContainers lists the containers created with `create`, leaving out
+// those that do not run unless all are asked for. The server itself lists
+// them as empty entries instead.
+func (f *fakeDocker) listContainers(w http.ResponseWriter, r *http.Request) {
+	list := []docker.APIContainers{}
+	for _, c := range f.created {
+		if c.State.Running || r.URL.Query().Get("all") == "1" {
+			list = append(list, docker.APIContainers{ID: c.ID, Image: c.Image, Names: []string{"/" + c.Name}})
+		}
+	}
+	jsonHandler(list).ServeHTTP(w, r)
+}
+
+// use makes the checks talk to the fake daemon, and returns a function
+// that undoes it.
+func (f *fakeDocker) use(t *testing.T) func() {
+	if err := UseDockerEndpoint(&Endpoint{Host: strings.TrimSuffix(f.URL(), "/")}); err != nil {
+		t.Fatal(err)
+	}
+	return func() { UseDockerClient(nil) }
+}
+
+// info makes the fake daemon answer `docker info` with `info`, given as
+// the JSON the daemon returns.
+func (f *fakeDocker) info(info string) {
+	f.CustomHandler("^/info$", rawHandler(info))
+}
+
+// inspect makes the fake daemon list and inspect `containers`, given as
+// the JSON the daemon returns for them, rather than the containers it
+// runs. The vendored client's types cannot hold much of what the checks
+// read of a container, such as its user namespace or its mounts.
+func (f *fakeDocker) inspect(containers ...string) {
+	var list []map[string]string
+	byID := make(map[string]string)
+	for _, data := range containers {
+		var c struct {
+			ID string `json:"Id"`
+		}
+		json.Unmarshal([]byte(data), &c)
+		list = append(list, map[string]string{"Id": c.ID})
+		byID[c.ID] = data
+	}
+	f.CustomHandler("^/containers/json$", jsonHandler(list))
+	f.CustomHandler("^/containers/[^/]+/json$", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := byID[strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/containers/"), "/json")]
+		if !ok {
+			http.Error(w, "No such container", http.StatusNotFound)
+			return
+		}
+		rawHandler(data).ServeHTTP(w, r)
+	}))
+}
+
+func rawHandler(data string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(data))
+	})
+}
+
+func jsonHandler(v interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	})
+}
+
+// create pulls the image of `config` and creates a container from it,
+// returning its ID. The fake daemon does not keep the host config a
+// container is created with, so it is set on the container the server
+// hands over.
+func (f *fakeDocker) create(t *testing.T, name string, config *docker.Config, hostConfig *docker.HostConfig) string {
+	if err := f.client.PullImage(docker.PullImageOptions{Repository: config.Image}, docker.AuthConfiguration{}); err != nil {
+		t.Fatal(err)
+	}
+	container, err := f.client.CreateContainer(docker.CreateContainerOptions{Name: name, Config: config, HostConfig: hostConfig})
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := <-f.containers
+	created.HostConfig = hostConfig
+	f.created = append(f.created, created)
+	return container.ID
+}
+
+// run creates and starts a container, as `docker run` would.
+func (f *fakeDocker) run(t *testing.T, name string, config *docker.Config, hostConfig *docker.HostConfig) {
+	id := f.create(t, name, config, hostConfig)
+	if err := f.client.StartContainer(id, nil); err != nil {
+		t.Fatal(err)
+	}
+	<-f.containers
+}
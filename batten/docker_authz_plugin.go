@@ -0,0 +1,46 @@
+package batten
+
+func (dc *DockerAuthzPluginCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+func (dc *DockerAuthzPluginCheck) AuditCheck() (bool, error) {
+	options, err := readDaemonOptions(dc.dockerPidFile)
+	if err != nil {
+		return false, err
+	}
+
+	return len(options.AuthorizationPlugins) > 0, nil
+}
+
+type DockerAuthzPluginCheck struct {
+	*CheckDefinitionImpl
+	DaemonCheck
+	dockerPidFile string
+}
+
+func makeDockerAuthzPluginCheck() Check {
+	return &DockerAuthzPluginCheck{
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "Batten-Daemon-Authz-Plugin",
+			level:       2,
+			category:    `Docker daemon configuration`,
+			name:        `Use an authorization plugin`,
+			description: `Run the Docker daemon with an authorization plugin, with '--authorization-plugin' or "authorization-plugins" in daemon.json, so that requests to the Docker API are allowed or denied by policy.`,
+			rationale:   `Without an authorization plugin, anyone who can reach the Docker API, through its socket or over the network, can do anything with it, including starting privileged containers that own the host. An authorization plugin can restrict who may do what, and keep a record of it.`,
+			auditDescription: `$> ps -ef | grep dockerd
+$> cat /etc/docker/daemon.json
+Ensure that '--authorization-plugin' or "authorization-plugins" names at least one plugin.`,
+			remediation: `Install an authorization plugin and start the daemon with it:
+
+$> dockerd --authorization-plugin=<plugin>
+
+or set "authorization-plugins": ["<plugin>"] in /etc/docker/daemon.json.`,
+			impact:       `Requests the plugin's policy does not allow are denied, and the daemon cannot serve requests while the plugin is unavailable.`,
+			defaultValue: `By default, no authorization plugin is used and every request is allowed.`,
+			references: []string{
+				"https://docs.docker.com/engine/extend/plugins_authorization/",
+			},
+		},
+	}
+}
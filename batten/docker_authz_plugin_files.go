@@ -0,0 +1,160 @@
+package batten
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"syscall"
+)
+
+// pluginSocketDirs hold the sockets of plugins, and pluginSpecDirs the
+// spec files of plugins listening elsewhere, as the daemon looks for them.
+var (
+	pluginSocketDirs = []string{"/run/docker/plugins"}
+	pluginSpecDirs   = []string{"/etc/docker/plugins", "/usr/lib/docker/plugins"}
+)
+
+// findPluginFiles returns the socket or spec files the daemon could find
+// the plugin `name` through: `<name>.sock`, `<name>.spec` or `<name>.json`,
+// directly in a plugin directory or in a directory named after the plugin.
+func findPluginFiles(name string) []string {
+	var files []string
+	candidates := func(dirs []string, exts ...string) {
+		for _, dir := range dirs {
+			for _, ext := range exts {
+				for _, filename := range []string{path.Join(dir, name+ext), path.Join(dir, name, name+ext)} {
+					if _, err := os.Stat(hostPath(filename)); err == nil {
+						files = append(files, filename)
+					}
+				}
+			}
+		}
+	}
+	candidates(pluginSocketDirs, ".sock")
+	candidates(pluginSpecDirs, ".sock", ".spec", ".json")
+	return files
+}
+
+// pluginFileFindings checks that a plugin file, and the directory it is
+// in, are owned by root and cannot be written by anyone else, who could
+// otherwise point the daemon at a plugin of their own.
+func pluginFileFindings(filename string) ([]Finding, error) {
+	var findings []Finding
+	for _, p := range []string{path.Dir(filename), filename} {
+		fi, err := os.Stat(hostPath(p))
+		if err != nil {
+			return nil, err
+		}
+		stat := fi.Sys().(*syscall.Stat_t)
+		if stat.Uid != 0 || stat.Gid != 0 {
+			findings = append(findings, Finding{
+				Subject: p,
+				Detail:  fmt.Sprintf("owned by %d:%d instead of root:root", stat.Uid, stat.Gid),
+			})
+		}
+		if perm := fi.Mode() & os.ModePerm; perm&0022 != 0 {
+			findings = append(findings, Finding{
+				Subject: p,
+				Detail:  fmt.Sprintf("can be written by its group or others (%04o)", perm),
+			})
+		}
+	}
+	return findings, nil
+}
+
+// managedPlugin is a plugin installed with `docker plugin install`.
+type managedPlugin struct {
+	Name    string
+	Enabled bool
+}
+
+// findManagedPlugin returns the installed plugin called `name`, with or
+// without its tag, or nil if there is none.
+func findManagedPlugin(name string) *managedPlugin {
+	var plugins []managedPlugin
+	if err := getDockerAPI("/plugins", &plugins); err != nil {
+		// daemons before managed plugins have no such endpoint
+		return nil
+	}
+	for i, p := range plugins {
+		if p.Name == name || strings.TrimSuffix(p.Name, ":latest") == name {
+			return &plugins[i]
+		}
+	}
+	return nil
+}
+
+func (dc *DockerAuthzPluginFilesCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+func (dc *DockerAuthzPluginFilesCheck) AuditFindings() (bool, []Finding, error) {
+	options, err := readDaemonOptions(dc.dockerPidFile)
+	if err != nil {
+		return false, nil, err
+	}
+
+	var findings []Finding
+	for _, name := range options.AuthorizationPlugins {
+		files := findPluginFiles(name)
+		if len(files) == 0 {
+			if plugin := findManagedPlugin(name); plugin == nil {
+				findings = append(findings, Finding{
+					Subject: name,
+					Detail:  "no socket or spec file, nor an installed plugin, was found for the authorization plugin",
+				})
+			} else if !plugin.Enabled {
+				findings = append(findings, Finding{
+					Subject: name,
+					Detail:  "the authorization plugin is installed but disabled",
+				})
+			}
+			continue
+		}
+		for _, filename := range files {
+			fileFindings, err := pluginFileFindings(filename)
+			if err != nil {
+				return false, nil, err
+			}
+			findings = append(findings, fileFindings...)
+		}
+	}
+	return len(findings) == 0, findings, nil
+}
+
+func (dc *DockerAuthzPluginFilesCheck) AuditCheck() (bool, error) {
+	succ, _, err := dc.AuditFindings()
+	return succ, err
+}
+
+type DockerAuthzPluginFilesCheck struct {
+	*CheckDefinitionImpl
+	DaemonCheck
+	dockerPidFile string
+}
+
+func makeDockerAuthzPluginFilesCheck() Check {
+	return &DockerAuthzPluginFilesCheck{
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "Batten-Authz-Plugin-Files",
+			level:       2,
+			category:    `Docker daemon configuration files`,
+			name:        `Verify the authorization plugins' sockets and spec files`,
+			description: `Verify that every configured authorization plugin can be found, through a socket in '/run/docker/plugins', a spec file in '/etc/docker/plugins' or '/usr/lib/docker/plugins', or as an installed plugin, and that its socket or spec file, and the directory it is in, are owned by root and writable by root only.`,
+			rationale:   `The daemon asks the plugin it finds under the configured name whether to allow each request. Whoever can replace that socket or spec file decides what the daemon allows. A plugin that cannot be found at all makes the daemon deny every request.`,
+			auditDescription: `$> ls -l /run/docker/plugins /etc/docker/plugins /usr/lib/docker/plugins
+$> docker plugin ls
+Ensure that every plugin given with '--authorization-plugin' is found, and that its files are owned by root:root with no write permission for group or others.`,
+			remediation: `Install the plugin, and restrict its files:
+
+#> chown root:root /run/docker/plugins/<plugin>.sock /etc/docker/plugins/<plugin>.spec
+#> chmod go-w /run/docker/plugins/<plugin>.sock /etc/docker/plugins/<plugin>.spec`,
+			defaultValue: `No authorization plugin is configured by default.`,
+			references: []string{
+				"https://docs.docker.com/engine/extend/plugin_api/#plugin-discovery",
+				"https://docs.docker.com/engine/extend/plugins_authorization/",
+			},
+		},
+	}
+}
@@ -0,0 +1,57 @@
+package batten
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindPluginFiles(t *testing.T) {
+	defer withHostFiles(t, map[string]string{
+		"/run/docker/plugins/opa/opa.sock":          "",
+		"/etc/docker/plugins/authz-broker.spec":     "unix:///var/run/authz-broker.sock",
+		"/usr/lib/docker/plugins/authz-broker.json": `{"Name": "authz-broker"}`,
+		"/etc/docker/plugins/other.spec":            "tcp://localhost:8080",
+	})()
+
+	tests := map[string][]string{
+		"opa":          {"/run/docker/plugins/opa/opa.sock"},
+		"authz-broker": {"/etc/docker/plugins/authz-broker.spec", "/usr/lib/docker/plugins/authz-broker.json"},
+		"missing":      nil,
+	}
+	for name, expected := range tests {
+		if files := findPluginFiles(name); !reflect.DeepEqual(files, expected) {
+			t.Errorf("%s: got %v, expected %v", name, files, expected)
+		}
+	}
+}
+
+func TestPluginFileFindings(t *testing.T) {
+	defer withHostFiles(t, map[string]string{
+		"/etc/docker/plugins/authz-broker.spec": "unix:///var/run/authz-broker.sock",
+	})()
+	if err := os.Chmod(hostPath("/etc/docker/plugins"), 0775); err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err := pluginFileFindings("/etc/docker/plugins/authz-broker.spec")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the test files are owned by whoever runs the test, so only the
+	// permissions are compared
+	var details []string
+	for _, finding := range findings {
+		if !strings.HasPrefix(finding.Detail, "owned") {
+			details = append(details, finding.Subject+": "+finding.Detail)
+		}
+	}
+	expected := []string{
+		"/etc/docker/plugins: can be written by its group or others (0775)",
+	}
+	if !reflect.DeepEqual(details, expected) {
+		t.Errorf("got %v, expected %v", details, expected)
+	}
+}
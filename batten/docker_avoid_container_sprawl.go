@@ -41,12 +41,14 @@ func (dc *DockerAvoidContainerSprawl) AuditCheck() (bool, error) {
 
 type DockerAvoidContainerSprawl struct {
 	*CheckDefinitionImpl
+	DockerAPICheck
 }
 
 func makeDockerAvoidContainerSprawl() Check {
 	return &DockerAvoidContainerSprawl{
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:  "CIS-Docker-Benchmark-6.7",
+			severity:    SeverityLow,
 			category:    "Docker Security Operations",
 			name:        "Avoid container sprawl",
 			impact:      "If you keep way too few number of containers per host, then perhaps you are not utilizing your host resources very adequately.",
@@ -7,7 +7,7 @@ func (dc *DockerAvoidImageSprawl) GetCheckDefinition() CheckDefinition {
 }
 
 func (dc *DockerAvoidImageSprawl) AuditCheck() (bool, error) {
-	client, err := docker.NewClient(DockerUnixSocket)
+	client, err := getDockerAPIConnection()
 
 	if err != nil {
 		// TODO: log error message
@@ -47,12 +47,14 @@ func (dc *DockerAvoidImageSprawl) AuditCheck() (bool, error) {
 
 type DockerAvoidImageSprawl struct {
 	*CheckDefinitionImpl
+	DockerAPICheck
 }
 
 func makeDockerAvoidImageSprawl() Check {
 	return &DockerAvoidImageSprawl{
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:  "CIS-Docker-Benchmark-6.6",
+			severity:    SeverityLow,
 			category:    "Docker Security Operations",
 			name:        "Avoid image sprawl",
 			impact:      "None",
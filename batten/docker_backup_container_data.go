@@ -17,6 +17,7 @@ func makeDockerBackupContainerData() Check {
 	return &DockerBackupContainerData{
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:  "CIS-Docker-Benchmark-6.4",
+			severity:    SeverityLow,
 			category:    "Docker Security Operations",
 			name:        `Backup container data`,
 			description: `Take regular backups of your container data volumes.`,
@@ -0,0 +1,90 @@
+package batten
+
+import "strings"
+
+// hostCgroups are the cgroups of users' sessions and of init, which
+// containers should not share limits with.
+var hostCgroups = map[string]bool{
+	"user.slice": true,
+	"init.scope": true,
+}
+
+// cgroupParentFindings checks the cgroup containers are placed under by
+// default.
+func cgroupParentFindings(options *DaemonOptions) []Finding {
+	parent := options.CgroupParent
+	if parent == "" {
+		return nil
+	}
+
+	var findings []Finding
+	name := strings.Trim(parent, "/")
+	switch {
+	case name == "" || name == "-.slice":
+		findings = append(findings, Finding{
+			Subject: parent,
+			Detail:  "containers are placed at the root of the cgroup hierarchy, outside of any limits",
+		})
+	case hostCgroups[name]:
+		findings = append(findings, Finding{
+			Subject: parent,
+			Detail:  "containers share the limits of the host's users or init",
+		})
+	}
+	if options.CgroupDriver == "systemd" && name != "" && !strings.HasSuffix(name, ".slice") {
+		findings = append(findings, Finding{
+			Subject: parent,
+			Detail:  "is not a slice, as the systemd cgroup driver requires",
+		})
+	}
+	return findings
+}
+
+func (dc *DockerCgroupParentCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+func (dc *DockerCgroupParentCheck) AuditFindings() (bool, []Finding, error) {
+	options, err := readDaemonOptions(dc.dockerPidFile, "cgroup-parent", "exec-opt")
+	if err != nil {
+		return false, nil, err
+	}
+
+	findings := cgroupParentFindings(options)
+	return len(findings) == 0, findings, nil
+}
+
+func (dc *DockerCgroupParentCheck) AuditCheck() (bool, error) {
+	succ, _, err := dc.AuditFindings()
+	return succ, err
+}
+
+type DockerCgroupParentCheck struct {
+	*CheckDefinitionImpl
+	DaemonCheck
+	dockerPidFile string
+}
+
+func makeDockerCgroupParentCheck() Check {
+	return &DockerCgroupParentCheck{
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "Batten-Daemon-Cgroup-Parent",
+			level:       2,
+			category:    `Docker daemon configuration`,
+			name:        `Confirm the default cgroup usage`,
+			description: `Verify that '--cgroup-parent', if it is set, places containers in a cgroup of their own, and not at the root of the hierarchy or with the host's user sessions or init.`,
+			rationale:   `The resource limits of a cgroup apply to everything in it. Containers placed at the root of the hierarchy escape the limits the host sets, and containers placed with users' sessions or init can starve them, or be starved by them. The default cgroup keeps containers apart from the rest of the host.`,
+			auditDescription: `$> ps -ef | grep dockerd
+$> cat /etc/docker/daemon.json
+Ensure that '--cgroup-parent' is either not set, or set to a cgroup meant for containers.`,
+			remediation: `Leave '--cgroup-parent' unset to use the default, or set it to a cgroup set up for the containers, such as a slice of their own with the systemd cgroup driver:
+
+$> dockerd --exec-opt native.cgroupdriver=systemd --cgroup-parent=containers.slice`,
+			impact:       `None.`,
+			defaultValue: `By default, containers are placed under '/docker', or under 'system.slice' as 'docker-<id>.scope' units with the systemd cgroup driver.`,
+			references: []string{
+				"https://docs.docker.com/engine/reference/commandline/dockerd/#default-cgroup-parent",
+			},
+		},
+	}
+}
@@ -0,0 +1,40 @@
+package batten
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCgroupParentFindings(t *testing.T) {
+	tests := []struct {
+		parent   string
+		driver   string
+		expected []string
+	}{
+		{"", "cgroupfs", nil},
+		{"", "systemd", nil},
+		{"/docker", "cgroupfs", nil},
+		{"docker.slice", "systemd", nil},
+		{"/", "cgroupfs", []string{"containers are placed at the root of the cgroup hierarchy, outside of any limits"}},
+		{"-.slice", "systemd", []string{"containers are placed at the root of the cgroup hierarchy, outside of any limits"}},
+		{"user.slice", "systemd", []string{"containers share the limits of the host's users or init"}},
+		{"/init.scope/", "cgroupfs", []string{"containers share the limits of the host's users or init"}},
+		{"/docker", "systemd", []string{"is not a slice, as the systemd cgroup driver requires"}},
+		{"init.scope", "systemd", []string{
+			"containers share the limits of the host's users or init",
+			"is not a slice, as the systemd cgroup driver requires",
+		}},
+	}
+	for _, test := range tests {
+		var details []string
+		for _, finding := range cgroupParentFindings(&DaemonOptions{CgroupParent: test.parent, CgroupDriver: test.driver}) {
+			if finding.Subject != test.parent {
+				t.Errorf("%q with %s: finding about %q", test.parent, test.driver, finding.Subject)
+			}
+			details = append(details, finding.Detail)
+		}
+		if !reflect.DeepEqual(details, test.expected) {
+			t.Errorf("%q with %s: got %q, expected %q", test.parent, test.driver, details, test.expected)
+		}
+	}
+}
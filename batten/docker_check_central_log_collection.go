@@ -1,20 +1,158 @@
 package batten
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
 func (dc *DockerCheckCentralLogCollection) GetCheckDefinition() CheckDefinition {
 	return dc
 }
 
+// logAddressOptions are the log drivers that send logs to a central
+// collector, and the log option each is given the collector's address in.
+var logAddressOptions = map[string]string{
+	"syslog":  "syslog-address",
+	"gelf":    "gelf-address",
+	"fluentd": "fluentd-address",
+	"splunk":  "splunk-url",
+	"awslogs": "awslogs-group",
+	"gcplogs": "",
+}
+
+func (dc *DockerCheckCentralLogCollection) usePolicy(policy *Policy) {
+	if policy.Logging != nil {
+		dc.policy = *policy.Logging
+	}
+}
+
+// logConfigFindings checks a log driver and its options against the
+// policy: the driver must be an allowed one, sending logs to the address
+// it is given, and a json-file driver must at least rotate its logs.
+func (dc *DockerCheckCentralLogCollection) logConfigFindings(subject string, driver string, opts map[string]string) []Finding {
+	var findings []Finding
+	switch driver {
+	case "none":
+		return []Finding{{Subject: subject, Detail: "logging is disabled with the 'none' log driver"}}
+	case "json-file":
+		// max-file defaults to 1, which rotates the log once it
+		// reaches max-size
+		if opts["max-size"] == "" {
+			findings = append(findings, Finding{
+				Subject: subject,
+				Detail:  "the 'json-file' log driver does not rotate its logs without max-size",
+			})
+		}
+	}
+
+	if !stringInSlice(driver, dc.policy.Allowed) {
+		findings = append(findings, Finding{
+			Subject: subject,
+			Detail:  fmt.Sprintf("the '%s' log driver does not send logs to a central collector; allowed are %s", driver, dc.allowedDriverNames()),
+		})
+	} else if address := logAddressOptions[driver]; address != "" && opts[address] == "" {
+		findings = append(findings, Finding{
+			Subject: subject,
+			Detail:  fmt.Sprintf("the '%s' log driver has no %s to send logs to", driver, address),
+		})
+	}
+	return findings
+}
+
+func (dc *DockerCheckCentralLogCollection) allowedDriverNames() string {
+	var names []string
+	for _, name := range dc.policy.Allowed {
+		names = append(names, "'"+name+"'")
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func (dc *DockerCheckCentralLogCollection) AuditFindings() (bool, []Finding, error) {
+	// the running daemon says which driver it uses; its options are only
+	// found in its configuration
+	var info struct {
+		LoggingDriver string
+	}
+	if err := getDockerAPI("/info", &info); err != nil {
+		return false, nil, err
+	}
+	driver, opts := info.LoggingDriver, map[string]string{}
+	options, err := readDaemonOptions(dc.dockerPidFile, "log-driver", "log-opt")
+	if err == nil {
+		opts = options.LogOpts
+		if driver == "" {
+			driver = options.LogDriver
+		}
+	} else if err != errDaemonNotRunning {
+		return false, nil, err
+	}
+
+	approved := dc.policy.Driver
+	if approved == "" {
+		approved = driver
+	}
+	daemonFindings := dc.logConfigFindings("default log driver", driver, opts)
+	if driver != approved {
+		daemonFindings = append(daemonFindings, Finding{
+			Subject: "default log driver",
+			Detail:  fmt.Sprintf("is '%s', not the approved '%s' log driver", driver, approved),
+		})
+	}
+	findings := daemonFindings
+
+	containers, err := inspectRunningContainers()
+	if err != nil {
+		return false, nil, err
+	}
+	for _, c := range containers {
+		logConfig := c.HostConfig.LogConfig
+		if logConfig.Type == driver && len(daemonFindings) > 0 {
+			// already reported for the daemon
+			continue
+		}
+		if logConfig.Type != approved {
+			findings = append(findings, Finding{
+				Subject: c.Subject(),
+				Detail:  fmt.Sprintf("overrides the approved '%s' log driver with '%s'", approved, logConfig.Type),
+			})
+		}
+
+		containerOpts := logConfig.Config
+		if logConfig.Type == driver {
+			containerOpts = make(map[string]string)
+			for k, v := range opts {
+				containerOpts[k] = v
+			}
+			for k, v := range logConfig.Config {
+				containerOpts[k] = v
+			}
+		}
+		findings = append(findings, dc.logConfigFindings(c.Subject(), logConfig.Type, containerOpts)...)
+	}
+	return len(findings) == 0, findings, nil
+}
+
 func (dc *DockerCheckCentralLogCollection) AuditCheck() (bool, error) {
-	// TODO
-	return true, nil
+	succ, _, err := dc.AuditFindings()
+	return succ, err
 }
 
 type DockerCheckCentralLogCollection struct {
 	*CheckDefinitionImpl
+	dockerPidFile string
+	policy        LoggingPolicy
 }
 
 func makeDockerCheckCentralLogCollection() Check {
+	var drivers []string
+	for driver := range logAddressOptions {
+		drivers = append(drivers, driver)
+	}
+	sort.Strings(drivers)
 	return &DockerCheckCentralLogCollection{
+		policy: LoggingPolicy{Allowed: drivers},
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:  "CIS-Docker-Benchmark-6.5",
 			category:    "Docker Security Operations",
@@ -25,8 +163,8 @@ func makeDockerCheckCentralLogCollection() Check {
 	•	Transient behavior of docker logs  
 	•	Difficulty in accessing application specific log files  
 	•	All stdout and stderr are logged  Hence, a centralized and remote log collection service should be utilized to keep logs for all the containers.`,
-			auditDescription: `First, verify that the centralized and remote log collection service is configured. Then verify that all the containers are logging at this centralized place.  Step 1: List all the running instances of containers by executing below command: docker ps -q  Step 2: For each container instance, execute the below command:  docker inspect --format='{{.Volumes}}' $INSTANCE_ID  Ensure that a centralized log volume is mounted on the containers.`,
-			remediation:      `Configure a centralized and remote log collection service. Some of the examples to do this are in references. Once the log collection service is active, configure all the containers to send their logs to this service.`,
+			auditDescription: `First, verify that the centralized and remote log collection service is configured. Then verify that all the containers are logging at this centralized place.  Step 1: Find the default log driver and its options: docker info --format '{{ .LoggingDriver }}' and the 'log-driver' and 'log-opts' of /etc/docker/daemon.json  Step 2: For each running container, execute the below command: docker inspect --format='{{ .HostConfig.LogConfig }}' $INSTANCE_ID  Ensure that the log drivers send logs to the collection service, such as 'syslog' with a 'syslog-address', that no container overrides the default log driver, and that no container logs with 'none' or with 'json-file' without 'max-size'.`,
+			remediation:      `Configure a centralized and remote log collection service. Some of the examples to do this are in references. Once the log collection service is active, make it the daemon's default log driver, for example in /etc/docker/daemon.json: {"log-driver": "syslog", "log-opts": {"syslog-address": "tcp://logs.example.com:514"}} and do not start containers with another '--log-driver'.`,
 			impact:           "None",
 			defaultValue:     `By default, each container logs separately.`,
 			references: []string{
@@ -0,0 +1,87 @@
+package batten
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLogConfigFindings(t *testing.T) {
+	check := makeDockerCheckCentralLogCollection().(*DockerCheckCentralLogCollection)
+	tests := []struct {
+		driver   string
+		opts     map[string]string
+		expected []string
+	}{
+		{"syslog", map[string]string{"syslog-address": "tcp://logs:514"}, nil},
+		{"gcplogs", nil, nil},
+		{"syslog", nil, []string{"the 'syslog' log driver has no syslog-address to send logs to"}},
+		{"none", nil, []string{"logging is disabled with the 'none' log driver"}},
+		// max-size alone rotates the log, as max-file defaults to 1
+		{"json-file", map[string]string{"max-size": "10m"}, []string{
+			"the 'json-file' log driver does not send logs to a central collector; allowed are 'awslogs', 'fluentd', 'gcplogs', 'gelf', 'splunk', 'syslog'",
+		}},
+		{"json-file", map[string]string{"max-file": "3"}, []string{
+			"the 'json-file' log driver does not rotate its logs without max-size",
+			"the 'json-file' log driver does not send logs to a central collector; allowed are 'awslogs', 'fluentd', 'gcplogs', 'gelf', 'splunk', 'syslog'",
+		}},
+	}
+	for _, test := range tests {
+		var details []string
+		for _, finding := range check.logConfigFindings("test", test.driver, test.opts) {
+			details = append(details, finding.Detail)
+		}
+		if !reflect.DeepEqual(details, test.expected) {
+			t.Errorf("%s %v: got %q, expected %q", test.driver, test.opts, details, test.expected)
+		}
+	}
+}
+
+func TestCentralLogCollection(t *testing.T) {
+	server := newFakeDocker(t)
+	defer server.Stop()
+	server.info(`{"LoggingDriver": "gelf"}`)
+	server.inspect(
+		`{"Id": "a1", "Name": "/default", "Config": {"Image": "nginx"}, "HostConfig": {"LogConfig": {"Type": "gelf"}}}`,
+		`{"Id": "b2", "Name": "/silent", "Config": {"Image": "batch"}, "HostConfig": {"LogConfig": {"Type": "none"}}}`,
+		`{"Id": "c3", "Name": "/elsewhere", "Config": {"Image": "api"}, "HostConfig": {"LogConfig": {"Type": "gelf", "Config": {"gelf-address": ""}}}}`,
+		`{"Id": "d4", "Name": "/switched", "Config": {"Image": "legacy"}, "HostConfig": {"LogConfig": {"Type": "syslog", "Config": {"syslog-address": "tcp://logs:514"}}}}`,
+	)
+	defer server.use(t)()
+
+	defer withProcesses(t, map[int]fakeProcess{
+		812: {cmdline: "dockerd --log-opt gelf-address=udp://logs:12201"},
+	})()
+	defer withHostFiles(t, nil)()
+
+	check := makeDockerCheckCentralLogCollection().(*DockerCheckCentralLogCollection)
+	succ, findings, err := check.AuditFindings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Finding{
+		{Subject: "silent (batch)", Detail: "overrides the approved 'gelf' log driver with 'none'"},
+		{Subject: "silent (batch)", Detail: "logging is disabled with the 'none' log driver"},
+		{Subject: "elsewhere (api)", Detail: "the 'gelf' log driver has no gelf-address to send logs to"},
+		// another allowed driver still overrides the daemon's
+		{Subject: "switched (legacy)", Detail: "overrides the approved 'gelf' log driver with 'syslog'"},
+	}
+	if succ || !reflect.DeepEqual(findings, expected) {
+		t.Errorf("got %v, expected %v", findings, expected)
+	}
+
+	// a policy that approves syslog fails the daemon, and the containers
+	// that use its driver are reported with it
+	check.usePolicy(&Policy{Logging: &LoggingPolicy{Driver: "syslog", Allowed: []string{"syslog", "gelf"}}})
+	succ, findings, err = check.AuditFindings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []Finding{
+		{Subject: "default log driver", Detail: "is 'gelf', not the approved 'syslog' log driver"},
+		{Subject: "silent (batch)", Detail: "overrides the approved 'syslog' log driver with 'none'"},
+		{Subject: "silent (batch)", Detail: "logging is disabled with the 'none' log driver"},
+	}
+	if succ || !reflect.DeepEqual(findings, expected) {
+		t.Errorf("with a policy, got %v, expected %v", findings, expected)
+	}
+}
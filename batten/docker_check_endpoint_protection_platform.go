@@ -17,6 +17,8 @@ func makeDockerCheckEndpointProtectionPlatform() Check {
 	return &DockerCheckEndpointProtectionPlatform{
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:  "CIS-Docker-Benchmark-6.3",
+			level:       2,
+			severity:    SeverityLow,
 			category:    "Docker Security Operations",
 			name:        `Endpoint protection platform (EPP) tools for containers`,
 			description: `There is no container-aware endpoint protection platform (EPP) solution as of now. You must rely on compensating controls to achieve the same.`,
@@ -5,18 +5,17 @@ func (dc *DockerInsecureRegistriesCheck) GetCheckDefinition() CheckDefinition {
 }
 
 func (dc *DockerInsecureRegistriesCheck) AuditCheck() (bool, error) {
-	succ, environ, err := readDockerDaemonEnviron(dc.dockerPidFile)
+	options, err := readDaemonOptions(dc.dockerPidFile, "insecure-registry")
 	if err != nil {
 		return false, err
 	}
-	if succ && environ["insecure-registry"] != "" {
-		return false, nil
-	}
-	return true, nil
+
+	return len(options.InsecureRegistries) == 0, nil
 }
 
 type DockerInsecureRegistriesCheck struct {
 	*CheckDefinitionImpl
+	DaemonCheck
 	// TODO: make configurable
 	dockerPidFile string
 }
@@ -25,6 +24,7 @@ func makeDockerInsecureRegistriesCheck() Check {
 	return &DockerInsecureRegistriesCheck{
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:  "CIS-Docker-Benchmark-2.5",
+			severity:    SeverityHigh,
 			category:    `Docker daemon configuration`,
 			name:        `Do not use insecure registries`,
 			description: `Docker considers a private registry either secure or insecure. By default, registries are considered secure.`,
@@ -46,6 +46,5 @@ $> docker -d --insecure-registry 10.1.0.0/16`,
 				"http://docs.docker.com/reference/commandline/cli/#insecure-registries",
 			},
 		},
-		dockerPidFile: "/var/run/docker.pid",
 	}
 }
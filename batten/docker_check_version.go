@@ -3,7 +3,6 @@ package batten
 import (
 	"errors"
 
-	docker "github.com/fsouza/go-dockerclient"
 	version "github.com/hashicorp/go-version"
 )
 
@@ -13,7 +12,7 @@ func (dc *DockerVersionCheck) GetCheckDefinition() CheckDefinition {
 
 func (dc *DockerVersionCheck) AuditCheck() (bool, error) {
 
-	client, err := docker.NewClient(DockerUnixSocket)
+	client, err := getDockerAPIConnection()
 
 	if err != nil {
 		// TODO: log error message
@@ -48,6 +47,7 @@ func (dc *DockerVersionCheck) AuditCheck() (bool, error) {
 
 type DockerVersionCheck struct {
 	*CheckDefinitionImpl
+	DockerAPICheck
 	endpoint      string
 	targetVersion string
 }
@@ -56,6 +56,7 @@ func makeDockerVersionCheck() Check {
 	return &DockerVersionCheck{
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier: "CIS-Docker-Benchmark-1.6",
+			severity:   SeverityHigh,
 			category:   "Host Configuration",
 			name:       "Keep Docker up to date",
 			impact:     "None",
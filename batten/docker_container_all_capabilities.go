@@ -0,0 +1,78 @@
+package batten
+
+// hasCapability tells whether `caps`, as given to '--cap-add' or
+// '--cap-drop', names `name`, with or without its CAP_ prefix.
+func hasCapability(caps []string, name string) bool {
+	for _, c := range caps {
+		if capabilityName(c) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (dc *DockerContainerAllCapabilitiesCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+// usePolicy defers to the check for 5.4 once the policy says which
+// capabilities containers may have, as keeping the default ones is then
+// only a problem for those the policy does not allow.
+func (dc *DockerContainerAllCapabilitiesCheck) usePolicy(policy *Policy) {
+	dc.deferred = policy.Capabilities != nil
+}
+
+// Applicable tells whether the check is run, rather than deferring to the
+// check for 5.4, which judges every running container against the policy.
+func (dc *DockerContainerAllCapabilitiesCheck) Applicable() bool {
+	return !dc.deferred
+}
+
+func (dc *DockerContainerAllCapabilitiesCheck) AuditFindings() (bool, []Finding, error) {
+	return auditRunningContainers(func(c *containerInspect) []string {
+		if c.HostConfig.Privileged || hasCapability(c.HostConfig.CapAdd, "ALL") {
+			// reported as privileged, or by 5.4 as having capabilities
+			// beyond the default ones
+			return nil
+		}
+		if !hasCapability(c.HostConfig.CapDrop, "ALL") {
+			return []string{"keeps Docker's default capabilities instead of '--cap-drop=ALL'"}
+		}
+		return nil
+	})
+}
+
+func (dc *DockerContainerAllCapabilitiesCheck) AuditCheck() (bool, error) {
+	succ, _, err := dc.AuditFindings()
+	return succ, err
+}
+
+type DockerContainerAllCapabilitiesCheck struct {
+	*CheckDefinitionImpl
+	DockerAPICheck
+
+	// whether the policy's capabilities are checked by 5.4 instead
+	deferred bool
+}
+
+func makeDockerContainerAllCapabilitiesCheck() Check {
+	return &DockerContainerAllCapabilitiesCheck{
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "Batten-Container-All-Capabilities",
+			level:       2,
+			category:    `Container Runtime`,
+			name:        `Drop all capabilities of containers`,
+			description: `Verify that every running container drops all capabilities with '--cap-drop=ALL', to add back only those it needs. Containers given more than the default capabilities, such as with '--cap-add=ALL', are reported by CIS-Docker-Benchmark-5.4, which takes over from this check when the policy has a 'capabilities' section, so that this check is then not applicable.`,
+			rationale:   `A container given every capability can, among others, mount filesystems, load kernel modules and trace other processes, which is as good as being privileged. Docker's default capabilities, such as NET_RAW, CHOWN or DAC_OVERRIDE, are more than most containers need.`,
+			auditDescription: `$> docker ps -q | xargs docker inspect --format '{{ .Name }}: CapAdd={{ .HostConfig.CapAdd }} CapDrop={{ .HostConfig.CapDrop }}'
+Ensure that every container has 'ALL' in CapDrop.`,
+			remediation:  `Start containers with '--cap-drop=ALL', and add back the capabilities they need with '--cap-add', for example: $> docker run --cap-drop=ALL --cap-add=NET_BIND_SERVICE <image>`,
+			impact:       `Containers that need some of Docker's default capabilities fail until they are added back.`,
+			defaultValue: `By default, containers get Docker's default set of capabilities.`,
+			references: []string{
+				"https://docs.docker.com/engine/reference/run/#runtime-privilege-and-linux-capabilities",
+				"http://man7.org/linux/man-pages/man7/capabilities.7.html",
+			},
+		},
+	}
+}
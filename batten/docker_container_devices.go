@@ -0,0 +1,76 @@
+package batten
+
+import (
+	"fmt"
+	"strings"
+)
+
+// permissiveDeviceRule tells whether a '--device-cgroup-rule', given as
+// `type major:minor access`, allows a whole class of devices rather than
+// one device.
+func permissiveDeviceRule(rule string) bool {
+	fields := strings.Fields(rule)
+	if len(fields) < 2 {
+		return true
+	}
+	if fields[0] == "a" {
+		return true
+	}
+	numbers := strings.SplitN(fields[1], ":", 2)
+	return numbers[0] == "*" || len(numbers) < 2 || numbers[1] == "*"
+}
+
+func (dc *DockerContainerDevicesCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+func (dc *DockerContainerDevicesCheck) AuditFindings() (bool, []Finding, error) {
+	return auditRunningContainers(func(c *containerInspect) []string {
+		var details []string
+		for _, d := range c.HostConfig.Devices {
+			details = append(details, fmt.Sprintf("is given the host device %s at %s (permissions '%s')", d.PathOnHost, d.PathInContainer, d.CgroupPermissions))
+		}
+		for _, rule := range c.HostConfig.DeviceCgroupRules {
+			if permissiveDeviceRule(rule) {
+				details = append(details, fmt.Sprintf("may use whole classes of devices with '--device-cgroup-rule=%s'", rule))
+			}
+		}
+		return details
+	})
+}
+
+func (dc *DockerContainerDevicesCheck) AuditCheck() (bool, error) {
+	succ, _, err := dc.AuditFindings()
+	return succ, err
+}
+
+type DockerContainerDevicesCheck struct {
+	*CheckDefinitionImpl
+	DockerAPICheck
+}
+
+func makeDockerContainerDevicesCheck() Check {
+	return &DockerContainerDevicesCheck{
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "CIS-Docker-Benchmark-5.17",
+			severity:    SeverityHigh,
+			category:    `Container Runtime`,
+			name:        `Do not directly expose host devices to containers`,
+			description: `Host devices can be directly exposed to containers at runtime. Do not directly expose host devices to containers especially for containers that are not trusted.`,
+			rationale:   `The '--device' option exposes the host devices to the containers and consequently the containers can directly access such host devices. You would not require the container to run in 'privileged' mode to access and manipulate the host devices. By default, the container will be able to read, write and mknod these devices. Additionally, it is possible for containers to remove block devices from the host. Hence, do not expose host devices to containers directly. A '--device-cgroup-rule' with a wildcard likewise lets the container use every device of a class, once it can create their device nodes.`,
+			auditDescription: `$> docker ps -q | xargs docker inspect --format '{{ .Name }}: Devices={{ .HostConfig.Devices }} DeviceCgroupRules={{ .HostConfig.DeviceCgroupRules }}'
+Verify that the host device is needed to be accessed from within the container and the permissions required are correctly set. Ensure that no device cgroup rule is of type 'a' or has a '*' major or minor number.`,
+			remediation: `Do not directly expose the host devices to containers. If at all, you need to expose the host devices to containers, use the correct set of permissions:
+For example, do not start a container as below:
+$> docker run --interactive --tty --device=/dev/tty0:/dev/tty0:rwm --device=/dev/temp_sda:/dev/temp_sda:rwm centos bash
+For example, share the host device with correct permissions:
+$> docker run --interactive --tty --device=/dev/tty0:/dev/tty0:rw --device=/dev/temp_sda:/dev/temp_sda:r centos bash`,
+			impact:       `You would not be able to use the host devices directly within the containers.`,
+			defaultValue: `By default, no host devices are exposed to containers. If you do not provide sharing permissions and choose to expose a host device to a container, the host device would be exposed with read, write and mknod permissions.`,
+			references: []string{
+				"https://docs.docker.com/engine/reference/commandline/run/#add-host-device-to-container---device",
+				"https://docs.docker.com/engine/reference/commandline/run/#dynamically-create-devices---device-cgroup-rule",
+			},
+		},
+	}
+}
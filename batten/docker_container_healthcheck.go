@@ -0,0 +1,54 @@
+package batten
+
+import "fmt"
+
+func (dc *DockerContainerHealthcheckCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+func (dc *DockerContainerHealthcheckCheck) AuditFindings() (bool, []Finding, error) {
+	return auditRunningContainers(func(c *containerInspect) []string {
+		healthcheck := c.Config.Healthcheck
+		switch {
+		case healthcheck != nil && len(healthcheck.Test) > 0 && healthcheck.Test[0] == "NONE":
+			return []string{"disables the health check of its image with '--no-healthcheck'"}
+		case c.State.Health == nil:
+			return []string{"has no health check, in its image or its container"}
+		case c.State.Health.Status == "unhealthy":
+			return []string{fmt.Sprintf("is unhealthy, after %d failed health checks in a row", c.State.Health.FailingStreak)}
+		}
+		return nil
+	})
+}
+
+func (dc *DockerContainerHealthcheckCheck) AuditCheck() (bool, error) {
+	succ, _, err := dc.AuditFindings()
+	return succ, err
+}
+
+type DockerContainerHealthcheckCheck struct {
+	*CheckDefinitionImpl
+	DockerAPICheck
+}
+
+func makeDockerContainerHealthcheckCheck() Check {
+	return &DockerContainerHealthcheckCheck{
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "Batten-Container-Healthcheck",
+			level:       2,
+			severity:    SeverityLow,
+			category:    `Container Runtime`,
+			name:        `Check container health at runtime`,
+			description: `Verify that every running container has a health check, from its image's HEALTHCHECK instruction or from '--health-cmd', that the daemon tracks in its state, and that no container is unhealthy.`,
+			rationale:   `A container that runs is not necessarily one that works. Without a health check, a hung or broken service goes unnoticed until its users notice, and an orchestrator cannot replace it. A container reported unhealthy is an incident in progress.`,
+			auditDescription: `$> docker ps --quiet | xargs docker inspect --format '{{ .Name }}: Healthcheck={{ .Config.Healthcheck }} Health={{ if .State.Health }}{{ .State.Health.Status }}{{ end }}'
+Ensure that every container has a health check, that it is not 'NONE', and that no container's health is 'unhealthy'.`,
+			remediation:  `Add a HEALTHCHECK instruction to the images, or start containers with '--health-cmd', and investigate unhealthy containers.`,
+			defaultValue: `By default, containers have the health check of their image, if it has one.`,
+			references: []string{
+				"https://docs.docker.com/engine/reference/builder/#healthcheck",
+				"https://docs.docker.com/engine/reference/run/#healthcheck",
+			},
+		},
+	}
+}
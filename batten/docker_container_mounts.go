@@ -0,0 +1,135 @@
+package batten
+
+import (
+	"path"
+	"strings"
+)
+
+// sensitiveHostPaths are the paths of the host a container should not
+// mount, nor anything under them but for "/".
+var sensitiveHostPaths = []string{"/", "/boot", "/dev", "/etc", "/proc", "/sys", DefaultDataRoot}
+
+// hostPathOf returns the path of the host a mount's source stands for,
+// with /var/run, which links to /run, resolved.
+func hostPathOf(source string) string {
+	p := path.Clean(source)
+	if p == "/var/run" || strings.HasPrefix(p, "/var/run/") {
+		p = strings.TrimPrefix(p, "/var")
+	}
+	return p
+}
+
+// underPath tells whether `p` is `dir` or is under it.
+func underPath(p string, dir string) bool {
+	return p == dir || strings.HasPrefix(p, strings.TrimSuffix(dir, "/")+"/")
+}
+
+func (dc *DockerContainerSensitiveMountsCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+func (dc *DockerContainerSensitiveMountsCheck) AuditFindings() (bool, []Finding, error) {
+	var info struct {
+		DockerRootDir string
+	}
+	if err := getDockerAPI("/info", &info); err != nil {
+		return false, nil, err
+	}
+	sensitive := sensitiveHostPaths
+	if info.DockerRootDir != "" && info.DockerRootDir != DefaultDataRoot {
+		sensitive = append(sensitive[:len(sensitive):len(sensitive)], info.DockerRootDir)
+	}
+
+	return auditRunningContainers(func(c *containerInspect) []string {
+		var details []string
+		for _, m := range c.Mounts {
+			if m.Type != "bind" {
+				continue
+			}
+			source := hostPathOf(m.Source)
+			for _, p := range sensitive {
+				if source == p || p != "/" && underPath(source, p) {
+					details = append(details, "mounts the sensitive host path "+p+": "+m.String())
+					break
+				}
+			}
+		}
+		return details
+	})
+}
+
+func (dc *DockerContainerSensitiveMountsCheck) AuditCheck() (bool, error) {
+	succ, _, err := dc.AuditFindings()
+	return succ, err
+}
+
+type DockerContainerSensitiveMountsCheck struct {
+	*CheckDefinitionImpl
+	DockerAPICheck
+}
+
+func makeDockerContainerSensitiveMountsCheck() Check {
+	return &DockerContainerSensitiveMountsCheck{
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "Batten-Container-Sensitive-Mounts",
+			severity:    SeverityHigh,
+			category:    `Container Runtime`,
+			name:        `Do not mount sensitive host system directories on containers`,
+			description: `Verify that no running container bind-mounts the host's '/', or '/boot', '/dev', '/etc', '/proc', '/sys' or the daemon's data root, or anything under them.`,
+			rationale:   `A container that can write to these directories can change the host's configuration, boot loader, devices or kernel settings, or the files of other containers. Even read-only, they expose secrets such as /etc/shadow and the files of other containers.`,
+			auditDescription: `$> docker ps -q | xargs docker inspect --format '{{ .Name }}: {{ range .Mounts }}{{ .Source }}:{{ .Destination }}:{{ .RW }}:{{ .Propagation }} {{ end }}'
+Ensure that no container mounts these directories, or anything under them. The findings say whether each mount is writable.`,
+			remediation:  `Do not mount sensitive host directories into containers. Give containers the files they need through volumes, configs or secrets instead, and mount host paths read-only when they must be.`,
+			defaultValue: `By default, containers do not mount host directories.`,
+			references: []string{
+				"https://docs.docker.com/storage/bind-mounts/",
+			},
+		},
+	}
+}
+
+func (dc *DockerContainerMountPropagationCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+func (dc *DockerContainerMountPropagationCheck) AuditFindings() (bool, []Finding, error) {
+	return auditRunningContainers(func(c *containerInspect) []string {
+		var details []string
+		for _, m := range c.Mounts {
+			if m.Propagation == "shared" || m.Propagation == "rshared" {
+				details = append(details, "shares mounts with the host: "+m.String())
+			}
+		}
+		return details
+	})
+}
+
+func (dc *DockerContainerMountPropagationCheck) AuditCheck() (bool, error) {
+	succ, _, err := dc.AuditFindings()
+	return succ, err
+}
+
+type DockerContainerMountPropagationCheck struct {
+	*CheckDefinitionImpl
+	DockerAPICheck
+}
+
+func makeDockerContainerMountPropagationCheck() Check {
+	return &DockerContainerMountPropagationCheck{
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "Batten-Container-Mount-Propagation",
+			category:    `Container Runtime`,
+			name:        `Do not set mount propagation mode to shared`,
+			description: `Verify that no running container has a mount with 'shared' or 'rshared' propagation.`,
+			rationale:   `A mount with shared propagation passes the mounts made under it in the container back to the host, so that a container allowed to mount can change what the host sees.`,
+			auditDescription: `$> docker ps -q | xargs docker inspect --format '{{ .Name }}: {{ range .Mounts }}{{ .Source }}:{{ .Destination }}:{{ .Propagation }} {{ end }}'
+Ensure that no mount has propagation 'shared' or 'rshared'.`,
+			remediation:  `Do not mount volumes with ':shared' or ':rshared', nor with 'bind-propagation=shared' or 'bind-propagation=rshared'.`,
+			defaultValue: `By default, mounts are 'rprivate'.`,
+			references: []string{
+				"https://docs.docker.com/storage/bind-mounts/#configure-bind-propagation",
+				"https://www.kernel.org/doc/Documentation/filesystems/sharedsubtree.txt",
+			},
+		},
+	}
+}
@@ -0,0 +1,73 @@
+package batten
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestContainerMounts(t *testing.T) {
+	server := newFakeDocker(t)
+	defer server.Stop()
+	server.info(`{"DockerRootDir": "/srv/docker"}`)
+	server.inspect(
+		`{"Id": "a1", "Name": "/web", "Config": {"Image": "nginx"}, "Mounts": [
+			{"Type": "volume", "Source": "/var/lib/docker/volumes/data/_data", "Destination": "/data", "RW": true, "Propagation": ""},
+			{"Type": "bind", "Source": "/srv/www", "Destination": "/usr/share/nginx/html", "Mode": "ro", "RW": false, "Propagation": "rprivate"}
+		]}`,
+		`{"Id": "b2", "Name": "/ci", "Config": {"Image": "jenkins"}, "Mounts": [
+			{"Type": "bind", "Source": "/var/run/docker.sock", "Destination": "/var/run/docker.sock", "Mode": "", "RW": true, "Propagation": "rprivate"},
+			{"Type": "bind", "Source": "/etc/ssl/certs", "Destination": "/certs", "Mode": "ro", "RW": false, "Propagation": "rprivate"}
+		]}`,
+		`{"Id": "c3", "Name": "/agent", "Config": {"Image": "agent"}, "Mounts": [
+			{"Type": "bind", "Source": "/", "Destination": "/host", "Mode": "", "RW": true, "Propagation": "rshared"},
+			{"Type": "bind", "Source": "/srv/docker/containers", "Destination": "/logs", "Mode": "ro", "RW": false, "Propagation": "rprivate"}
+		], "HostConfig": {"Devices": [{"PathOnHost": "/dev/sda", "PathInContainer": "/dev/sda", "CgroupPermissions": "rwm"}], "DeviceCgroupRules": ["c 1:3 rw", "b *:* rwm"]}}`,
+	)
+	defer server.use(t)()
+
+	tests := []struct {
+		check    Check
+		expected []Finding
+	}{
+		{makeDockerContainerSensitiveMountsCheck(), []Finding{
+			{Subject: "ci (jenkins)", Detail: "mounts the sensitive host path /etc: /etc/ssl/certs on /certs (read-only, mode 'ro', propagation 'rprivate')"},
+			{Subject: "agent (agent)", Detail: "mounts the sensitive host path /: / on /host (writable, mode '', propagation 'rshared')"},
+			{Subject: "agent (agent)", Detail: "mounts the sensitive host path /srv/docker: /srv/docker/containers on /logs (read-only, mode 'ro', propagation 'rprivate')"},
+		}},
+		{makeDockerContainerRuntimeSocketCheck(), []Finding{
+			{Subject: "ci (jenkins)", Detail: "mounts the socket /run/docker.sock, and so controls the host: /var/run/docker.sock on /var/run/docker.sock (writable, mode '', propagation 'rprivate')"},
+			{Subject: "agent (agent)", Detail: "mounts the socket /run/docker.sock, and so controls the host: / on /host (writable, mode '', propagation 'rshared')"},
+		}},
+		{makeDockerContainerMountPropagationCheck(), []Finding{
+			{Subject: "agent (agent)", Detail: "shares mounts with the host: / on /host (writable, mode '', propagation 'rshared')"},
+		}},
+		{makeDockerContainerDevicesCheck(), []Finding{
+			{Subject: "agent (agent)", Detail: "is given the host device /dev/sda at /dev/sda (permissions 'rwm')"},
+			{Subject: "agent (agent)", Detail: "may use whole classes of devices with '--device-cgroup-rule=b *:* rwm'"},
+		}},
+	}
+	for _, test := range tests {
+		succ, findings, err := test.check.(findingsCheck).AuditFindings()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if succ || !reflect.DeepEqual(findings, test.expected) {
+			t.Errorf("%s: got %v, expected %v", test.check.GetCheckDefinition().Identifier(), findings, test.expected)
+		}
+	}
+}
+
+func TestPermissiveDeviceRule(t *testing.T) {
+	tests := map[string]bool{
+		"c 1:3 rw":   false,
+		"c 189:* rw": true,
+		"b *:* rwm":  true,
+		"a":          true,
+		"a *:* rwm":  true,
+	}
+	for rule, expected := range tests {
+		if permissiveDeviceRule(rule) != expected {
+			t.Errorf("%s: expected permissive to be %v", rule, expected)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package batten
+
+func (dc *DockerContainerNoNewPrivilegesCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+func (dc *DockerContainerNoNewPrivilegesCheck) AuditFindings() (bool, []Finding, error) {
+	daemonDefault := false
+	options, err := readDaemonOptions(dc.dockerPidFile, "no-new-privileges")
+	if err == nil {
+		daemonDefault = options.NoNewPrivileges
+	} else if err != errDaemonNotRunning {
+		return false, nil, err
+	}
+
+	return auditRunningContainers(func(c *containerInspect) []string {
+		value, found := c.SecurityOption("no-new-privileges")
+		switch {
+		case found && value == "false":
+			return []string{"opts out of no-new-privileges with '--security-opt=no-new-privileges=false'"}
+		case !found && !daemonDefault:
+			return []string{"runs without '--security-opt=no-new-privileges'"}
+		}
+		return nil
+	})
+}
+
+func (dc *DockerContainerNoNewPrivilegesCheck) AuditCheck() (bool, error) {
+	succ, _, err := dc.AuditFindings()
+	return succ, err
+}
+
+type DockerContainerNoNewPrivilegesCheck struct {
+	*CheckDefinitionImpl
+	dockerPidFile string
+}
+
+func makeDockerContainerNoNewPrivilegesCheck() Check {
+	return &DockerContainerNoNewPrivilegesCheck{
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "Batten-Container-No-New-Privileges",
+			category:    `Container Runtime`,
+			name:        `Restrict containers from acquiring additional privileges`,
+			description: `Verify that every running container runs with no-new-privileges, given with '--security-opt=no-new-privileges' or as the daemon's default with '--no-new-privileges', and that none opts out of the daemon's default.`,
+			rationale:   `Without no-new-privileges, a process in a container can gain privileges through setuid or setgid binaries or file capabilities, turning a compromised unprivileged user of the container into root in it.`,
+			auditDescription: `$> docker ps -q | xargs docker inspect --format '{{ .Name }}: SecurityOpt={{ .HostConfig.SecurityOpt }}'
+$> ps -ef | grep dockerd
+Ensure that every container has 'no-new-privileges' in its SecurityOpt, or that the daemon runs with '--no-new-privileges' and no container sets 'no-new-privileges=false'.`,
+			remediation:  `Start containers with '--security-opt=no-new-privileges', or run the daemon with '--no-new-privileges'.`,
+			impact:       `Programs in the container that rely on setuid or setgid binaries, such as 'su' or 'sudo', stop working.`,
+			defaultValue: `By default, containers can acquire new privileges.`,
+			references: []string{
+				"https://docs.docker.com/engine/reference/run/#security-configuration",
+				"https://www.kernel.org/doc/Documentation/prctl/no_new_privs.txt",
+			},
+		},
+	}
+}
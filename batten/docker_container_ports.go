@@ -0,0 +1,284 @@
+package batten
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sensitivePorts are the ports of services that should not be reachable
+// from outside the host.
+var sensitivePorts = map[int]string{
+	22:    "SSH",
+	1433:  "SQL Server",
+	1521:  "Oracle",
+	2375:  "the Docker API",
+	2376:  "the Docker API",
+	2379:  "etcd",
+	2380:  "etcd",
+	3306:  "MySQL",
+	5432:  "PostgreSQL",
+	5984:  "CouchDB",
+	6379:  "Redis",
+	9200:  "Elasticsearch",
+	10250: "the kubelet API",
+	11211: "memcached",
+	27017: "MongoDB",
+}
+
+// publishedPort is a port of a container published on the host.
+type publishedPort struct {
+	HostIP        string
+	HostPort      int
+	ContainerPort string
+}
+
+func (p publishedPort) String() string {
+	ip := p.HostIP
+	if ip == "" {
+		ip = "0.0.0.0"
+	}
+	return fmt.Sprintf("%s->%s", net.JoinHostPort(ip, strconv.Itoa(p.HostPort)), p.ContainerPort)
+}
+
+// allInterfaces tells whether the port is published on every address of
+// the host.
+func (p publishedPort) allInterfaces() bool {
+	ip := net.ParseIP(p.HostIP)
+	return p.HostIP == "" || ip != nil && ip.IsUnspecified()
+}
+
+// containerPortNumber returns the number of the port in the container,
+// which is given as `port/protocol`.
+func (p publishedPort) containerPortNumber() int {
+	port, _ := strconv.Atoi(strings.SplitN(p.ContainerPort, "/", 2)[0])
+	return port
+}
+
+// publishedPorts returns the ports the container publishes on the host:
+// those the daemon bound for it, or those it asks for if it does not run.
+// A running container without bound ports publishes none, whatever it
+// asks for, e.g. on the host's network.
+func publishedPorts(c *containerInspect) []publishedPort {
+	bindings := c.NetworkSettings.Ports
+	if !c.State.Running {
+		bindings = c.HostConfig.PortBindings
+	}
+
+	var ports []publishedPort
+	for containerPort, hostPorts := range bindings {
+		for _, b := range hostPorts {
+			hostPort, _ := strconv.Atoi(b.HostPort)
+			ports = append(ports, publishedPort{HostIP: b.HostIP, HostPort: hostPort, ContainerPort: containerPort})
+		}
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].HostPort != ports[j].HostPort {
+			return ports[i].HostPort < ports[j].HostPort
+		}
+		if ports[i].HostIP != ports[j].HostIP {
+			return ports[i].HostIP < ports[j].HostIP
+		}
+		return ports[i].ContainerPort < ports[j].ContainerPort
+	})
+	return ports
+}
+
+func (dc *DockerContainerPortCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+// allowed tells whether the policy lets the container publish `port` on
+// the host.
+func (dc *DockerContainerPortCheck) allowed(c *containerInspect, port publishedPort) bool {
+	for i := range dc.policy.Containers {
+		rule := &dc.policy.Containers[i]
+		if !rule.matches(c.Config.Image, c.Name) {
+			continue
+		}
+		for _, allowed := range rule.Allowed {
+			if allowed == port.HostPort {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (dc *DockerContainerPortCheck) usePolicy(policy *Policy) {
+	if policy.Ports != nil {
+		dc.policy = *policy.Ports
+	}
+}
+
+func (dc *DockerContainerPortCheck) AuditFindings() (bool, []Finding, error) {
+	return auditRunningContainers(func(c *containerInspect) []string {
+		var details []string
+		for _, port := range publishedPorts(c) {
+			if !dc.everyPort && dc.allowed(c, port) {
+				continue
+			}
+			if detail := dc.audit(dc, c, port); detail != "" {
+				details = append(details, detail)
+			}
+		}
+		return details
+	})
+}
+
+func (dc *DockerContainerPortCheck) AuditCheck() (bool, error) {
+	succ, _, err := dc.AuditFindings()
+	return succ, err
+}
+
+type DockerContainerPortCheck struct {
+	*CheckDefinitionImpl
+	DockerAPICheck
+	audit  func(dc *DockerContainerPortCheck, c *containerInspect, port publishedPort) string
+	policy PortPolicy
+
+	// everyPort makes the check audit the ports the policy allows too
+	everyPort bool
+}
+
+func newDockerContainerPortCheck(audit func(dc *DockerContainerPortCheck, c *containerInspect, port publishedPort) string, definition *CheckDefinitionImpl) Check {
+	definition.category = `Container Runtime`
+	return &DockerContainerPortCheck{
+		CheckDefinitionImpl: definition,
+		audit:               audit,
+	}
+}
+
+func makeDockerContainerPrivilegedPortsCheck() Check {
+	return newDockerContainerPortCheck(func(dc *DockerContainerPortCheck, c *containerInspect, port publishedPort) string {
+		if port.HostPort > 0 && port.HostPort < 1024 {
+			return fmt.Sprintf("maps the privileged host port %d: %s", port.HostPort, port)
+		}
+		return ""
+	}, &CheckDefinitionImpl{
+		identifier:  "CIS-Docker-Benchmark-5.7",
+		name:        `Do not map privileged ports within containers`,
+		description: `The TCP/IP port numbers below 1024 are considered privileged ports. Normal users and processes are not allowed to use them for various security reasons. Docker allows a container port to be mapped to a privileged port.`,
+		rationale:   `By default, if the user does not specifically declare the container port to host port mapping, Docker automatically and correctly maps the container port to one available in 49153-65535 block on the host. But, Docker allows a container port to be mapped to a privileged port on the host if the user explicitly declared it. This is so because containers are executed with NET_BIND_SERVICE Linux kernel capability that does not restrict the privileged port mapping. The privileged ports receive and transmit various sensitive and privileged data. Allowing containers to use them can bring serious implications.`,
+		auditDescription: `$> docker ps --quiet | xargs docker inspect --format '{{ .Name }}: Ports={{ .NetworkSettings.Ports }}'
+Review the list and ensure that container ports are not mapped to host port numbers below 1024, unless allowed to by the 'ports' section of the policy.`,
+		remediation:  `Do not map the container ports to privileged host ports when starting a container. Also, ensure that there is no such container to host privileged port mapping declarations in the Dockerfile.`,
+		impact:       `None.`,
+		defaultValue: `By default, mapping a container port to a privileged port on the host is allowed.`,
+		references: []string{
+			"https://docs.docker.com/engine/reference/commandline/run/#publish-or-expose-port--p---expose",
+		},
+	})
+}
+
+func makeDockerContainerPublishAllPortsCheck() Check {
+	return newDockerContainerPortCheck(func(dc *DockerContainerPortCheck, c *containerInspect, port publishedPort) string {
+		if _, asked := c.HostConfig.PortBindings[port.ContainerPort]; c.HostConfig.PublishAllPorts && !asked {
+			return fmt.Sprintf("publishes every port its image exposes with '--publish-all': %s", port)
+		}
+		return ""
+	}, &CheckDefinitionImpl{
+		identifier:  "CIS-Docker-Benchmark-5.8",
+		name:        `Open only needed ports on container`,
+		description: `Dockerfile for a container image defines the ports to be opened by default on a container instance. The list of ports may or may not be relevant to the application you are running within the container.`,
+		rationale:   `A container can be run just with the ports defined in the Dockerfile for its image or can be arbitrarily passed run time parameters to open a list of ports. Additionally, Overtime, Dockerfile may undergo various changes and the list of exposed ports may or may not be relevant to the application you are running within the container. Opening unneeded ports increase the attack surface of the container and the containerized application. As a recommended practice, do not open unneeded ports.`,
+		auditDescription: `$> docker ps --quiet | xargs docker inspect --format '{{ .Name }}: PublishAllPorts={{ .HostConfig.PublishAllPorts }} Ports={{ .NetworkSettings.Ports }}'
+Review the list and ensure that the ports mapped are the ones really needed for the container, and that no container is started with '-P' ('--publish-all').`,
+		remediation:  `Fix the Dockerfile of the container image to expose only needed ports by your containerized application. You can also completely ignore the list of ports defined in the Dockerfile by NOT using '-P' (UPPERCASE) or '--publish-all' flag when starting the container. Use the '-p' (lowercase) or '--publish' flag to explicitly define the ports that you need for a particular container instance.`,
+		impact:       `None.`,
+		defaultValue: `By default, all the ports that are listed in the Dockerfile under EXPOSE instruction for an image are opened when a container is run with '-P' or '--publish-all' flag.`,
+		references: []string{
+			"https://docs.docker.com/engine/reference/commandline/run/#publish-or-expose-port--p---expose",
+		},
+	})
+}
+
+func makeDockerContainerBindInterfaceCheck() Check {
+	return newDockerContainerPortCheck(func(dc *DockerContainerPortCheck, c *containerInspect, port publishedPort) string {
+		if len(dc.policy.Interfaces) == 0 {
+			if port.allInterfaces() {
+				return fmt.Sprintf("is reachable on every interface of the host: %s", port)
+			}
+			return ""
+		}
+		ip := net.ParseIP(port.HostIP)
+		for _, address := range dc.policy.Interfaces {
+			if ip != nil && ip.Equal(net.ParseIP(address)) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("is not published on an interface the policy allows: %s", port)
+	}, &CheckDefinitionImpl{
+		identifier:  "CIS-Docker-Benchmark-5.13",
+		level:       2,
+		name:        `Bind incoming container traffic to a specific host interface`,
+		description: `By default, Docker containers can make connections to the outside world, but the outside world cannot connect to containers. Each outgoing connection will appear to originate from one of the host machine's own IP addresses. Only allow container services to be contacted through a specific external interface on the host machine.`,
+		rationale:   `If you have multiple network interfaces on your host machine, the container can accept connections on the exposed ports on any network interface. This might not be desired and may not be secured. Many a times a particular interface is exposed externally and services such as intrusion detection, intrusion prevention, firewall, load balancing, etc. are run on those interfaces to screen incoming public traffic. Hence, you should not accept incoming connections on any interface. You should only allow incoming connections from a particular external interface.`,
+		auditDescription: `$> docker ps --quiet | xargs docker inspect --format '{{ .Name }}: Ports={{ .NetworkSettings.Ports }}'
+Review the list and ensure that the exposed container ports are tied to a particular interface and not to the wildcard IP address - 0.0.0.0 or ::. When the 'ports' section of the policy lists interfaces, ports must be published on one of them.`,
+		remediation: `Bind the container port to a specific host interface on the desired host port.
+For example,
+$> docker run --detach --publish 10.2.3.4:49153:80 nginx
+In the example above, the container port 80 is bound to the host port on 49153 and would accept incoming connection only from 10.2.3.4 external interface.`,
+		impact:       `None.`,
+		defaultValue: `By default, Docker exposes the container ports on 0.0.0.0, the wildcard IP address that will match any possible incoming network interface on the host machine.`,
+		references: []string{
+			"https://docs.docker.com/engine/reference/commandline/run/#publish-or-expose-port--p---expose",
+		},
+	})
+}
+
+func makeDockerContainerSensitivePortsCheck() Check {
+	return newDockerContainerPortCheck(func(dc *DockerContainerPortCheck, c *containerInspect, port publishedPort) string {
+		if ip := net.ParseIP(port.HostIP); ip != nil && ip.IsLoopback() {
+			return ""
+		}
+		service, ok := sensitivePorts[port.containerPortNumber()]
+		if !ok {
+			service, ok = sensitivePorts[port.HostPort]
+		}
+		if ok {
+			return fmt.Sprintf("exposes %s on the host: %s", service, port)
+		}
+		return ""
+	}, &CheckDefinitionImpl{
+		identifier:  "Batten-Container-Sensitive-Ports",
+		name:        `Do not publish the ports of sensitive services`,
+		description: `Verify that no running container publishes, on the host, the port of a service that should not be reachable from outside it, such as SSH (22), the Docker API (2375, 2376), etcd, the kubelet or a database.`,
+		rationale:   `Such services are the first things attackers look for. A database or a Docker API published on the host, on whatever host port, is reachable by anyone who can reach the host, while its clients are usually other containers that can reach it over a Docker network without it being published.`,
+		auditDescription: `$> docker ps --quiet | xargs docker inspect --format '{{ .Name }}: Ports={{ .NetworkSettings.Ports }}'
+Ensure that none of 22, 1433, 1521, 2375, 2376, 2379, 2380, 3306, 5432, 5984, 6379, 9200, 10250, 11211 and 27017 is published, as a port of the container or of the host, unless allowed to by the 'ports' section of the policy.`,
+		remediation:  `Do not publish the ports of these services. Connect their clients to them over a Docker network, or publish them on the loopback or a management interface only, e.g. '-p 127.0.0.1:5432:5432'.`,
+		defaultValue: `By default, no port of a container is published.`,
+		references: []string{
+			"https://docs.docker.com/config/containers/container-networking/#published-ports",
+		},
+	})
+}
+
+func makeDockerContainerExposedPortsCheck() Check {
+	check := newDockerContainerPortCheck(func(dc *DockerContainerPortCheck, c *containerInspect, port publishedPort) string {
+		if ip := net.ParseIP(port.HostIP); ip != nil && ip.IsLoopback() {
+			return ""
+		}
+		return fmt.Sprintf("is reachable from outside the host: %s", port)
+	}, &CheckDefinitionImpl{
+		identifier:  "Batten-Container-Exposed-Ports",
+		level:       2,
+		severity:    SeverityLow,
+		name:        `Review the ports containers make reachable from outside the host`,
+		description: `List every port a running container publishes on an address of the host other than the loopback, whether on every interface, on a specific one, or as the 'ports' section of the policy allows.`,
+		rationale:   `The other port checks report what is wrong with a published port, so that a port published on an allowed interface, or allowed by the policy, is not among their findings. What is reachable from outside the host is still worth reviewing as a whole, against what the host's firewall lets in.`,
+		auditDescription: `$> docker ps --quiet | xargs docker inspect --format '{{ .Name }}: Ports={{ .NetworkSettings.Ports }}'
+Review every port that is not published on 127.0.0.1 or ::1.`,
+		remediation:  `Publish only the ports that need to be reachable from outside the host, and publish the others on the loopback, e.g. '-p 127.0.0.1:8080:80'.`,
+		defaultValue: `By default, no port of a container is published.`,
+		references: []string{
+			"https://docs.docker.com/config/containers/container-networking/#published-ports",
+		},
+	}).(*DockerContainerPortCheck)
+	check.everyPort = true
+	return check
+}
@@ -0,0 +1,131 @@
+package batten
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestContainerPorts(t *testing.T) {
+	server := newFakeDocker(t)
+	defer server.Stop()
+	server.inspect(
+		`{"Id": "a1", "Name": "/ingress", "State": {"Running": true}, "Config": {"Image": "nginx"},
+			"HostConfig": {"PortBindings": {"443/tcp": [{"HostIp": "", "HostPort": "443"}]}},
+			"NetworkSettings": {"Ports": {"443/tcp": [{"HostIp": "0.0.0.0", "HostPort": "443"}, {"HostIp": "::", "HostPort": "443"}], "8080/tcp": null}}}`,
+		`{"Id": "b2", "Name": "/db", "State": {"Running": true}, "Config": {"Image": "postgres:16"},
+			"HostConfig": {"PublishAllPorts": true},
+			"NetworkSettings": {"Ports": {"5432/tcp": [{"HostIp": "0.0.0.0", "HostPort": "32768"}]}}}`,
+		`{"Id": "c3", "Name": "/cache", "State": {"Running": true}, "Config": {"Image": "redis"},
+			"HostConfig": {"PortBindings": {"6379/tcp": [{"HostIp": "127.0.0.1", "HostPort": "6379"}]}},
+			"NetworkSettings": {"Ports": {"6379/tcp": [{"HostIp": "127.0.0.1", "HostPort": "6379"}]}}}`,
+		`{"Id": "d4", "Name": "/ssh", "State": {"Running": true}, "Config": {"Image": "sshd"},
+			"HostConfig": {"PortBindings": {"22/tcp": [{"HostIp": "10.0.0.5", "HostPort": "2222"}]}},
+			"NetworkSettings": {"Ports": {"22/tcp": [{"HostIp": "10.0.0.5", "HostPort": "2222"}]}}}`,
+		`{"Id": "e5", "Name": "/agent", "State": {"Running": true}, "Config": {"Image": "agent"},
+			"HostConfig": {"NetworkMode": "host", "PortBindings": {"22/tcp": [{"HostIp": "", "HostPort": "22"}]}}}`,
+	)
+	defer server.use(t)()
+
+	tests := []struct {
+		check    Check
+		expected []Finding
+	}{
+		{makeDockerContainerPrivilegedPortsCheck(), []Finding{
+			{Subject: "ingress (nginx)", Detail: "maps the privileged host port 443: 0.0.0.0:443->443/tcp"},
+			{Subject: "ingress (nginx)", Detail: "maps the privileged host port 443: [::]:443->443/tcp"},
+		}},
+		{makeDockerContainerPublishAllPortsCheck(), []Finding{
+			{Subject: "db (postgres:16)", Detail: "publishes every port its image exposes with '--publish-all': 0.0.0.0:32768->5432/tcp"},
+		}},
+		{makeDockerContainerBindInterfaceCheck(), []Finding{
+			{Subject: "ingress (nginx)", Detail: "is reachable on every interface of the host: 0.0.0.0:443->443/tcp"},
+			{Subject: "ingress (nginx)", Detail: "is reachable on every interface of the host: [::]:443->443/tcp"},
+			{Subject: "db (postgres:16)", Detail: "is reachable on every interface of the host: 0.0.0.0:32768->5432/tcp"},
+		}},
+		{makeDockerContainerSensitivePortsCheck(), []Finding{
+			{Subject: "db (postgres:16)", Detail: "exposes PostgreSQL on the host: 0.0.0.0:32768->5432/tcp"},
+			{Subject: "ssh (sshd)", Detail: "exposes SSH on the host: 10.0.0.5:2222->22/tcp"},
+		}},
+		{makeDockerContainerExposedPortsCheck(), []Finding{
+			{Subject: "ingress (nginx)", Detail: "is reachable from outside the host: 0.0.0.0:443->443/tcp"},
+			{Subject: "ingress (nginx)", Detail: "is reachable from outside the host: [::]:443->443/tcp"},
+			{Subject: "db (postgres:16)", Detail: "is reachable from outside the host: 0.0.0.0:32768->5432/tcp"},
+			{Subject: "ssh (sshd)", Detail: "is reachable from outside the host: 10.0.0.5:2222->22/tcp"},
+		}},
+	}
+	for _, test := range tests {
+		succ, findings, err := test.check.(findingsCheck).AuditFindings()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if succ || !reflect.DeepEqual(findings, test.expected) {
+			t.Errorf("%s: got %v, expected %v", test.check.GetCheckDefinition().Identifier(), findings, test.expected)
+		}
+	}
+
+	// with a policy, ports must be published on its interfaces, and
+	// containers may publish the ports it allows them
+	check := makeDockerContainerBindInterfaceCheck()
+	check.(policyCheck).usePolicy(&Policy{Ports: &PortPolicy{
+		Interfaces: []string{"10.0.0.5", "127.0.0.1"},
+		Containers: []PortRule{{ContainerPattern: ContainerPattern{Name: "ingress"}, Allowed: []int{443}}},
+	}})
+	_, findings, err := check.(findingsCheck).AuditFindings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Finding{
+		{Subject: "db (postgres:16)", Detail: "is not published on an interface the policy allows: 0.0.0.0:32768->5432/tcp"},
+	}
+	if !reflect.DeepEqual(findings, expected) {
+		t.Errorf("got %v, expected %v", findings, expected)
+	}
+
+	// the ports the policy allows are still reachable
+	check = makeDockerContainerExposedPortsCheck()
+	check.(policyCheck).usePolicy(&Policy{Ports: &PortPolicy{
+		Containers: []PortRule{{ContainerPattern: ContainerPattern{Name: "ingress"}, Allowed: []int{443}}},
+	}})
+	_, findings, err = check.(findingsCheck).AuditFindings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 4 {
+		t.Errorf("expected every port reachable from outside the host, got %v", findings)
+	}
+}
+
+func TestPublishedPorts(t *testing.T) {
+	tests := []struct {
+		inspect  string
+		expected []string
+	}{
+		{`{}`, nil},
+		// the ports the daemon bound win over those asked for
+		{`{"State": {"Running": true}, "NetworkSettings": {"Ports": {"80/tcp": [{"HostIp": "0.0.0.0", "HostPort": "8080"}, {"HostIp": "::", "HostPort": "8080"}], "22/tcp": null}},
+		   "HostConfig": {"PortBindings": {"443/tcp": [{"HostIp": "", "HostPort": "8443"}]}}}`,
+			[]string{"0.0.0.0:8080->80/tcp", "[::]:8080->80/tcp"}},
+		// a container that does not run has only asked for its ports
+		{`{"HostConfig": {"PortBindings": {"443/tcp": [{"HostIp": "", "HostPort": "8443"}], "53/udp": [{"HostIp": "127.0.0.1", "HostPort": "53"}]}}}`,
+			[]string{"127.0.0.1:53->53/udp", "0.0.0.0:8443->443/tcp"}},
+		// the daemon binds no port of a container on the host's network
+		{`{"State": {"Running": true}, "HostConfig": {"NetworkMode": "host", "PortBindings": {"80/tcp": [{"HostIp": "", "HostPort": "80"}]}}}`, nil},
+		// ports of both protocols on the same host port
+		{`{"State": {"Running": true}, "NetworkSettings": {"Ports": {"53/udp": [{"HostIp": "0.0.0.0", "HostPort": "53"}], "53/tcp": [{"HostIp": "0.0.0.0", "HostPort": "53"}]}}}`,
+			[]string{"0.0.0.0:53->53/tcp", "0.0.0.0:53->53/udp"}},
+	}
+	for _, test := range tests {
+		c := &containerInspect{}
+		if err := json.Unmarshal([]byte(test.inspect), c); err != nil {
+			t.Fatal(err)
+		}
+		var ports []string
+		for _, port := range publishedPorts(c) {
+			ports = append(ports, port.String())
+		}
+		if !reflect.DeepEqual(ports, test.expected) {
+			t.Errorf("%s: got %v, expected %v", test.inspect, ports, test.expected)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package batten
+
+func (dc *DockerContainerPrivilegedCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+func (dc *DockerContainerPrivilegedCheck) AuditFindings() (bool, []Finding, error) {
+	return auditRunningContainers(func(c *containerInspect) []string {
+		if c.HostConfig.Privileged {
+			return []string{"runs with '--privileged'"}
+		}
+		return nil
+	})
+}
+
+func (dc *DockerContainerPrivilegedCheck) AuditCheck() (bool, error) {
+	succ, _, err := dc.AuditFindings()
+	return succ, err
+}
+
+type DockerContainerPrivilegedCheck struct {
+	*CheckDefinitionImpl
+	DockerAPICheck
+}
+
+func makeDockerContainerPrivilegedCheck() Check {
+	return &DockerContainerPrivilegedCheck{
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "Batten-Container-Privileged",
+			severity:    SeverityHigh,
+			category:    `Container Runtime`,
+			name:        `Do not use privileged containers`,
+			description: `Verify that no running container was started with '--privileged'.`,
+			rationale:   `A privileged container is given every capability, access to all the host's devices, and no seccomp, AppArmor or SELinux confinement. Root in it can mount the host's disks or load kernel modules, and so is root on the host, whatever other checks say of its capabilities or profiles.`,
+			auditDescription: `$> docker ps -q | xargs docker inspect --format '{{ .Name }}: Privileged={{ .HostConfig.Privileged }}'
+Ensure that no container is privileged.`,
+			remediation:  `Do not run containers with '--privileged'. Give them the capabilities and devices they need with '--cap-add' and '--device' instead.`,
+			impact:       `Containers that manage the host, such as some monitoring or storage agents, need the capabilities and devices they used to get from '--privileged' to be given explicitly.`,
+			defaultValue: `By default, containers are not privileged.`,
+			references: []string{
+				"https://docs.docker.com/engine/reference/run/#runtime-privilege-and-linux-capabilities",
+			},
+		},
+	}
+}
@@ -0,0 +1,93 @@
+package batten
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSecurityOption(t *testing.T) {
+	c := &containerInspect{}
+	c.HostConfig.SecurityOpt = []string{"no-new-privileges", "seccomp:unconfined", "apparmor=docker-custom"}
+	tests := []struct {
+		name  string
+		value string
+		found bool
+	}{
+		{"no-new-privileges", "true", true},
+		{"seccomp", "unconfined", true},
+		{"apparmor", "docker-custom", true},
+		{"label", "", false},
+	}
+	for _, test := range tests {
+		value, found := c.SecurityOption(test.name)
+		if value != test.value || found != test.found {
+			t.Errorf("%s: got %q %v, expected %q %v", test.name, value, found, test.value, test.found)
+		}
+	}
+}
+
+func TestContainerPrivileges(t *testing.T) {
+	server := newFakeDocker(t)
+	defer server.Stop()
+	server.inspect(
+		`{"Id": "a1", "Name": "/locked", "Config": {"Image": "nginx"}, "HostConfig": {"SecurityOpt": ["no-new-privileges"], "CapDrop": ["ALL"], "CapAdd": ["NET_BIND_SERVICE"]}}`,
+		`{"Id": "b2", "Name": "/god", "Config": {"Image": "agent:1"}, "HostConfig": {"Privileged": true}}`,
+		`{"Id": "c3", "Name": "/loose", "Config": {"Image": "debug"}, "HostConfig": {"SecurityOpt": ["seccomp=unconfined", "apparmor:unconfined", "no-new-privileges=false"], "CapAdd": ["all"]}}`,
+		`{"Id": "d4", "Name": "/plain", "Config": {"Image": "app"}, "HostConfig": {"SecurityOpt": ["no-new-privileges"]}}`,
+	)
+	defer server.use(t)()
+
+	defer withProcesses(t, map[int]fakeProcess{
+		812: {cmdline: "dockerd"},
+	})()
+	defer withHostFiles(t, nil)()
+
+	tests := []struct {
+		check    Check
+		expected []Finding
+	}{
+		{makeDockerContainerPrivilegedCheck(), []Finding{
+			{Subject: "god (agent:1)", Detail: "runs with '--privileged'"},
+		}},
+		{makeDockerContainerNoNewPrivilegesCheck(), []Finding{
+			{Subject: "god (agent:1)", Detail: "runs without '--security-opt=no-new-privileges'"},
+			{Subject: "loose (debug)", Detail: "opts out of no-new-privileges with '--security-opt=no-new-privileges=false'"},
+		}},
+		{makeDockerContainerUnconfinedCheck(), []Finding{
+			{Subject: "loose (debug)", Detail: "runs without a seccomp profile, with '--security-opt=seccomp=unconfined'"},
+			{Subject: "loose (debug)", Detail: "runs without an AppArmor profile, with '--security-opt=apparmor=unconfined'"},
+		}},
+		// '--cap-add=ALL' is left to 5.4
+		{makeDockerContainerAllCapabilitiesCheck(), []Finding{
+			{Subject: "plain (app)", Detail: "keeps Docker's default capabilities instead of '--cap-drop=ALL'"},
+		}},
+	}
+	for _, test := range tests {
+		succ, findings, err := test.check.(findingsCheck).AuditFindings()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if succ || !reflect.DeepEqual(findings, test.expected) {
+			t.Errorf("%s: got %v, expected %v", test.check.GetCheckDefinition().Identifier(), findings, test.expected)
+		}
+	}
+
+	// with a capabilities policy, 5.4 says which capabilities may be kept
+	check := makeDockerContainerAllCapabilitiesCheck().(*DockerContainerAllCapabilitiesCheck)
+	check.usePolicy(&Policy{Capabilities: &CapabilityPolicy{Allowed: defaultCapabilities}})
+	if results := RunCheck(check); !results.NotApplicable || results.Success {
+		t.Errorf("with a policy, expected the check not to apply, got %+v", results)
+	}
+
+	// the daemon's default covers containers that do not opt out
+	defer withProcesses(t, map[int]fakeProcess{
+		812: {cmdline: "dockerd --no-new-privileges"},
+	})()
+	_, findings, err := makeDockerContainerNoNewPrivilegesCheck().(findingsCheck).AuditFindings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].Subject != "loose (debug)" {
+		t.Errorf("unexpected findings %v", findings)
+	}
+}
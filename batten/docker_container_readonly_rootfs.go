@@ -0,0 +1,122 @@
+package batten
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// tmpfsMissingOptions returns which of noexec and nosuid a tmpfs mounted
+// with `options` goes without. The daemon mounts tmpfs with both unless
+// given 'exec' or 'suid'.
+func tmpfsMissingOptions(options string) []string {
+	var missing []string
+	for _, opt := range []string{"exec", "suid"} {
+		if stringInSlice(opt, strings.Split(options, ",")) {
+			missing = append(missing, "no"+opt)
+		}
+	}
+	return missing
+}
+
+// mountOptions returns the options of a tmpfs mounted with '--mount
+// type=tmpfs', which are given as a list of names or name/value pairs, in
+// the form '--tmpfs' takes them.
+func mountOptions(options [][]string) string {
+	var opts []string
+	for _, option := range options {
+		opts = append(opts, strings.Join(option, "="))
+	}
+	return strings.Join(opts, ",")
+}
+
+// tmpfsFindings reports the tmpfs mounts of the container that let what is
+// written there be executed, or gain privileges through setuid binaries.
+func tmpfsFindings(c *containerInspect) []string {
+	found := make(map[string]string)
+	for destination, options := range c.HostConfig.Tmpfs {
+		if missing := tmpfsMissingOptions(options); len(missing) > 0 {
+			found[destination] = fmt.Sprintf("mounts a tmpfs on %s without %s (options '%s')", destination, strings.Join(missing, " and "), options)
+		}
+	}
+	for _, m := range c.HostConfig.Mounts {
+		if m.Type != "tmpfs" || m.TmpfsOptions == nil {
+			continue
+		}
+		options := mountOptions(m.TmpfsOptions.Options)
+		if missing := tmpfsMissingOptions(options); len(missing) > 0 {
+			found[m.Target] = fmt.Sprintf("mounts a tmpfs on %s without %s (options '%s')", m.Target, strings.Join(missing, " and "), options)
+		}
+	}
+
+	var destinations []string
+	for destination := range found {
+		destinations = append(destinations, destination)
+	}
+	sort.Strings(destinations)
+	var details []string
+	for _, destination := range destinations {
+		details = append(details, found[destination])
+	}
+	return details
+}
+
+func (dc *DockerContainerReadonlyRootfsCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+func (dc *DockerContainerReadonlyRootfsCheck) usePolicy(policy *Policy) {
+	if policy.Rootfs != nil {
+		dc.policy = *policy.Rootfs
+	}
+}
+
+func (dc *DockerContainerReadonlyRootfsCheck) AuditFindings() (bool, []Finding, error) {
+	return auditRunningContainers(func(c *containerInspect) []string {
+		for i := range dc.policy.Containers {
+			if dc.policy.Containers[i].matches(c.Config.Image, c.Name) {
+				return nil
+			}
+		}
+
+		var details []string
+		if !c.HostConfig.ReadonlyRootfs {
+			details = append(details, "runs with a writable root filesystem, without '--read-only'")
+		}
+		return append(details, tmpfsFindings(c)...)
+	})
+}
+
+func (dc *DockerContainerReadonlyRootfsCheck) AuditCheck() (bool, error) {
+	succ, _, err := dc.AuditFindings()
+	return succ, err
+}
+
+type DockerContainerReadonlyRootfsCheck struct {
+	*CheckDefinitionImpl
+	DockerAPICheck
+	policy RootfsPolicy
+}
+
+func makeDockerContainerReadonlyRootfsCheck() Check {
+	return &DockerContainerReadonlyRootfsCheck{
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "CIS-Docker-Benchmark-5.12",
+			level:       2,
+			category:    `Container Runtime`,
+			name:        `Mount container's root filesystem as read only`,
+			description: `The container's root file system should be treated as a 'golden image' and any writes to the root filesystem should be avoided. You should explicitly define a container volume for writing.`,
+			rationale:   `You should not be writing data within containers. The data volume belonging to a container should be explicitly defined and administered. This is useful in many cases where the admin controls where they would want developers to write files and errors. Also, this has other advantages such as below: This leads to an immutable infrastructure. Since the container instance cannot be written to, there is no need to audit instance divergence. Reduced security attack vectors since the instance cannot be tampered with or written to. Ability to use a purely volume based backup without backing up anything from the instance. The tmpfs mounts that give such containers room to write should not let what is written there be executed, nor gain privileges through setuid binaries.`,
+			auditDescription: `$> docker ps --quiet | xargs docker inspect --format '{{ .Name }}: ReadonlyRootfs={{ .HostConfig.ReadonlyRootfs }} Tmpfs={{ .HostConfig.Tmpfs }} Mounts={{ .HostConfig.Mounts }}'
+If the above command returns 'false', it means the container's root filesystem is writable. Ensure too that no tmpfs mount, given with '--tmpfs' or '--mount type=tmpfs', has the 'exec' or 'suid' option. Containers listed in the 'rootfs' section of the policy are not reported.`,
+			remediation: `Add a '--read-only' flag at a container's runtime to enforce the container's root filesystem to be mounted as read only, and give it the writable paths it needs with volumes or tmpfs mounts:
+$> docker run <Run arguments> --read-only --tmpfs /run:rw,noexec,nosuid --volume data:/data <Container Image Name or ID> <Command>`,
+			impact:       `Enabling '--read-only' at container runtime may break some container OS packages if a data writing strategy is not defined. You should define what the container's data should and should not persist at runtime in order to determine which recommendation applies.`,
+			defaultValue: `By default, a container will have its root filesystem writable allowing all container processes to write files owned by the container's runtime user.`,
+			references: []string{
+				"http://docs.docker.com/engine/reference/commandline/run/#mount-tmpfs---tmpfs",
+				"https://docs.docker.com/storage/tmpfs/",
+			},
+		},
+	}
+}
@@ -0,0 +1,65 @@
+package batten
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestContainerReadonlyRootfs(t *testing.T) {
+	server := newFakeDocker(t)
+	defer server.Stop()
+	server.inspect(
+		`{"Id": "a1", "Name": "/immutable", "Config": {"Image": "nginx"}, "HostConfig": {"ReadonlyRootfs": true, "Tmpfs": {"/run": "", "/tmp": "rw,size=64m"}}}`,
+		`{"Id": "b2", "Name": "/builder", "Config": {"Image": "builder"}, "HostConfig": {"ReadonlyRootfs": true, "Tmpfs": {"/work": "rw,exec", "/bin-cache": "exec,suid"}}}`,
+		`{"Id": "c3", "Name": "/app", "Config": {"Image": "app"}}`,
+		`{"Id": "d4", "Name": "/wordpress", "Config": {"Image": "legacy/wordpress:6"}}`,
+		`{"Id": "e5", "Name": "/mounted", "Config": {"Image": "api"},
+			"HostConfig": {"ReadonlyRootfs": true, "Tmpfs": {"/run": ""}, "Mounts": [
+				{"Type": "tmpfs", "Target": "/cache", "TmpfsOptions": {"SizeBytes": 67108864}},
+				{"Type": "tmpfs", "Target": "/defaults"},
+				{"Type": "tmpfs", "Target": "/scratch", "TmpfsOptions": {"Options": [["exec"]]}},
+				{"Type": "tmpfs", "Target": "/tmp", "TmpfsOptions": {"Options": [["exec"], ["suid"]]}},
+				{"Type": "volume", "Source": "data", "Target": "/data"}]},
+			"Mounts": [
+				{"Type": "tmpfs", "Source": "", "Destination": "/cache", "RW": true},
+				{"Type": "tmpfs", "Source": "", "Destination": "/defaults", "RW": true},
+				{"Type": "tmpfs", "Source": "", "Destination": "/scratch", "RW": true},
+				{"Type": "tmpfs", "Source": "", "Destination": "/tmp", "RW": true},
+				{"Type": "volume", "Name": "data", "Source": "/var/lib/docker/volumes/data/_data", "Destination": "/data", "RW": true}]}`,
+	)
+	defer server.use(t)()
+
+	check := makeDockerContainerReadonlyRootfsCheck().(*DockerContainerReadonlyRootfsCheck)
+	check.usePolicy(&Policy{Rootfs: &RootfsPolicy{Containers: []ContainerPattern{{Image: "legacy/*"}}}})
+	succ, findings, err := check.AuditFindings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Finding{
+		{Subject: "builder (builder)", Detail: "mounts a tmpfs on /bin-cache without noexec and nosuid (options 'exec,suid')"},
+		{Subject: "builder (builder)", Detail: "mounts a tmpfs on /work without noexec (options 'rw,exec')"},
+		{Subject: "app (app)", Detail: "runs with a writable root filesystem, without '--read-only'"},
+		{Subject: "mounted (api)", Detail: "mounts a tmpfs on /scratch without noexec (options 'exec')"},
+		{Subject: "mounted (api)", Detail: "mounts a tmpfs on /tmp without noexec and nosuid (options 'exec,suid')"},
+	}
+	if succ || !reflect.DeepEqual(findings, expected) {
+		t.Errorf("got %v, expected %v", findings, expected)
+	}
+}
+
+func TestTmpfsMissingOptions(t *testing.T) {
+	tests := map[string][]string{
+		"":                     nil,
+		"rw,size=64m":          nil,
+		"noexec,nosuid":        nil,
+		"rw,exec":              {"noexec"},
+		"suid,size=1g":         {"nosuid"},
+		"exec,suid,mode=1777":  {"noexec", "nosuid"},
+		"rw,executable,suidly": nil,
+	}
+	for options, expected := range tests {
+		if missing := tmpfsMissingOptions(options); !reflect.DeepEqual(missing, expected) {
+			t.Errorf("%q: got %v, expected %v", options, missing, expected)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package batten
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// resourceLimit is a limit a container should run with: whether its
+// HostConfig asks for it, and whether its cgroup enforces it, saying what
+// the cgroup has if not. `unlimited` reports what else is wrong with a
+// container that runs without the limit.
+type resourceLimit struct {
+	name       string
+	configured func(c *containerInspect) bool
+	enforced   func(cg *processCgroup) (bool, string, bool)
+	unlimited  func(c *containerInspect) []string
+}
+
+var memoryLimit = resourceLimit{
+	name: "memory limit",
+	configured: func(c *containerInspect) bool {
+		return c.HostConfig.Memory > 0
+	},
+	enforced: func(cg *processCgroup) (bool, string, bool) {
+		if cg.v1("memory") {
+			value, ok := cg.read("memory", "memory.limit_in_bytes", "")
+			if !ok {
+				return false, "", false
+			}
+			// unlimited is the largest page-aligned int64
+			limit, err := strconv.ParseInt(value, 10, 64)
+			return err == nil && limit < 1<<62, "memory.limit_in_bytes is " + value, true
+		}
+		value, ok := cg.read("memory", "", "memory.max")
+		return value != "max", "memory.max is " + value, ok
+	},
+	unlimited: func(c *containerInspect) []string {
+		if c.HostConfig.OomKillDisable {
+			return []string{"disables the OOM killer without a memory limit, so that it can exhaust the host's memory"}
+		}
+		return nil
+	},
+}
+
+var cpuLimit = resourceLimit{
+	name: "CPU share or quota",
+	configured: func(c *containerInspect) bool {
+		return c.HostConfig.CPUShares > 0 && c.HostConfig.CPUShares != 1024 || c.HostConfig.CPUQuota > 0 || c.HostConfig.NanoCPUs > 0
+	},
+	enforced: func(cg *processCgroup) (bool, string, bool) {
+		if cg.v1("cpu") {
+			quota, ok1 := cg.read("cpu", "cpu.cfs_quota_us", "")
+			shares, ok2 := cg.read("cpu", "cpu.shares", "")
+			return quota != "-1" || shares != "1024", fmt.Sprintf("cpu.cfs_quota_us is %s and cpu.shares is %s", quota, shares), ok1 && ok2
+		}
+		max, ok1 := cg.read("cpu", "", "cpu.max")
+		weight, ok2 := cg.read("cpu", "", "cpu.weight")
+		return !strings.HasPrefix(max, "max") || weight != "100", fmt.Sprintf("cpu.max is '%s' and cpu.weight is %s", max, weight), ok1 && ok2
+	},
+}
+
+var pidsLimit = resourceLimit{
+	name: "PIDs limit",
+	configured: func(c *containerInspect) bool {
+		return c.HostConfig.PidsLimit > 0
+	},
+	enforced: func(cg *processCgroup) (bool, string, bool) {
+		value, ok := cg.read("pids", "pids.max", "pids.max")
+		return value != "max", "pids.max is " + value, ok
+	},
+}
+
+// limitFindings checks that the container runs with the check's limit,
+// from its cgroup when it runs on this host, or from its HostConfig
+// otherwise. A limit its HostConfig asks for that cannot be read from its
+// cgroup is reported as not verified.
+func (dc *DockerContainerResourceCheck) limitFindings(c *containerInspect) ([]string, bool, error) {
+	configured := dc.limit.configured(c)
+	if c.State.Pid > 0 {
+		cg, err := readProcessCgroup(c.State.Pid)
+		if err != nil {
+			return nil, false, err
+		}
+		if cg != nil {
+			enforced, value, known := dc.limit.enforced(cg)
+			switch {
+			case !known && configured && len(cg.unresolved) > 0:
+				return []string{fmt.Sprintf("is configured with a %s that could not be verified (cgroup %s not found on the host)", dc.limit.name, strings.Join(cg.unresolved, ", "))}, false, nil
+			case !known && configured:
+				return []string{fmt.Sprintf("is configured with a %s that could not be verified from its cgroup", dc.limit.name)}, false, nil
+			case !known:
+			case enforced:
+				return nil, true, nil
+			case configured:
+				return []string{fmt.Sprintf("is configured with a %s its cgroup does not enforce (%s)", dc.limit.name, value)}, false, nil
+			default:
+				return []string{fmt.Sprintf("runs without a %s (%s)", dc.limit.name, value)}, false, nil
+			}
+		}
+	}
+	if configured {
+		return nil, true, nil
+	}
+	return []string{"runs without a " + dc.limit.name}, false, nil
+}
+
+func (dc *DockerContainerResourceCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+func (dc *DockerContainerResourceCheck) AuditFindings() (bool, []Finding, error) {
+	var auditErr error
+	succ, findings, err := auditRunningContainers(func(c *containerInspect) []string {
+		details, limited, err := dc.limitFindings(c)
+		if err != nil {
+			auditErr = err
+			return nil
+		}
+		if !limited && dc.limit.unlimited != nil {
+			details = append(details, dc.limit.unlimited(c)...)
+		}
+		return details
+	})
+	if err == nil {
+		err = auditErr
+	}
+	if err != nil {
+		return false, nil, err
+	}
+	return succ, findings, nil
+}
+
+func (dc *DockerContainerResourceCheck) AuditCheck() (bool, error) {
+	succ, _, err := dc.AuditFindings()
+	return succ, err
+}
+
+type DockerContainerResourceCheck struct {
+	*CheckDefinitionImpl
+	limit resourceLimit
+}
+
+func newDockerContainerResourceCheck(limit resourceLimit, definition *CheckDefinitionImpl) Check {
+	definition.category = `Container Runtime`
+	return &DockerContainerResourceCheck{
+		CheckDefinitionImpl: definition,
+		limit:               limit,
+	}
+}
+
+func makeDockerContainerMemoryCheck() Check {
+	return newDockerContainerResourceCheck(memoryLimit, &CheckDefinitionImpl{
+		identifier:  "CIS-Docker-Benchmark-5.10",
+		name:        `Limit memory usage for container`,
+		description: `By default, all containers on a Docker host share the resources equally. By using the resource management capabilities of Docker host, such as memory limit, you can control the amount of memory that a container may consume.`,
+		rationale:   `By default, container can use all of the memory on the host. You can use memory limit mechanism to prevent a denial of service arising from one container consuming all of the host's resources such that other containers on the same host cannot perform their intended functions. Having no limit on memory can lead to issues where one container can easily make the whole system unstable and as a result unusable. A container that also disables the OOM killer cannot even be stopped by the kernel when it does.`,
+		auditDescription: `$> docker ps -q | xargs docker inspect --format '{{ .Name }}: Pid={{ .State.Pid }} Memory={{ .HostConfig.Memory }} OomKillDisable={{ .HostConfig.OomKillDisable }}'
+$> cat /proc/<pid>/cgroup
+$> cat /sys/fs/cgroup/<path>/memory.max (cgroup v2) or /sys/fs/cgroup/memory/<path>/memory.limit_in_bytes (cgroup v1)
+If the memory is 0 or the cgroup file says 'max' or a value near 2^63, memory limits are not in place. Ensure too that no container sets OomKillDisable without a memory limit.`,
+		remediation: `Run the container with only as much memory as required. Always run the container using the '--memory' argument.
+For example, you could run a container as below:
+$> docker run --interactive --tty --memory 256m centos /bin/bash`,
+		impact:       `If you do not set proper limits, the container process may have to starve.`,
+		defaultValue: `By default, all containers on a Docker host share the resources equally. No memory limits are enforced.`,
+		references: []string{
+			"https://docs.docker.com/config/containers/resource_constraints/#memory",
+			"https://www.kernel.org/doc/Documentation/cgroup-v2.txt",
+		},
+	})
+}
+
+func makeDockerContainerCPUCheck() Check {
+	return newDockerContainerResourceCheck(cpuLimit, &CheckDefinitionImpl{
+		identifier:  "CIS-Docker-Benchmark-5.11",
+		name:        `Set container CPU priority appropriately`,
+		description: `By default, all containers on a Docker host share the resources equally. By using the resource management capabilities of Docker host, such as CPU shares, you can control the host CPU resources that a container may consume.`,
+		rationale:   `By default, CPU time is divided between containers equally. If it is desired, to control the CPU time amongst the container instances, you can use CPU sharing feature. CPU sharing allows to prioritize one container over the other and forbids the lower priority container to claim CPU resources more often. This ensures that the high priority containers are served better.`,
+		auditDescription: `$> docker ps -q | xargs docker inspect --format '{{ .Name }}: Pid={{ .State.Pid }} CpuShares={{ .HostConfig.CpuShares }} CpuQuota={{ .HostConfig.CpuQuota }} NanoCpus={{ .HostConfig.NanoCpus }}'
+$> cat /proc/<pid>/cgroup
+$> cat /sys/fs/cgroup/<path>/cpu.max /sys/fs/cgroup/<path>/cpu.weight (cgroup v2) or /sys/fs/cgroup/cpu,cpuacct/<path>/cpu.cfs_quota_us /sys/fs/cgroup/cpu,cpuacct/<path>/cpu.shares (cgroup v1)
+If the CPU shares are 0 or 1024 and there is no quota, or the cgroup has the default weight (100, or 1024 shares) and no quota, CPU shares are not in place.`,
+		remediation: `Manage the CPU shares or quota between your containers. To do so start the container using the '--cpu-shares' or '--cpus' argument.
+For example, you could run a container as below:
+$> docker run --interactive --tty --cpu-shares 512 centos /bin/bash
+In the above example, the container is started with CPU shares of 50% of what the other containers use.`,
+		impact:       `If you do not correctly assign the CPU shares, the container process may have to starve if the resources on the host are not freed. If the CPU resources on the host are free, CPU shares do not place any restrictions on the CPU that the container may use.`,
+		defaultValue: `By default, all containers on a Docker host share the resources equally. No CPU shares are enforced.`,
+		references: []string{
+			"https://docs.docker.com/config/containers/resource_constraints/#cpu",
+			"https://www.kernel.org/doc/Documentation/cgroup-v2.txt",
+		},
+	})
+}
+
+func makeDockerContainerPidsCheck() Check {
+	return newDockerContainerResourceCheck(pidsLimit, &CheckDefinitionImpl{
+		identifier:  "Batten-Container-Pids-Limit",
+		name:        `Limit the number of processes of containers`,
+		description: `Verify that every running container runs with a PIDs limit, given with '--pids-limit', and that its cgroup enforces it.`,
+		rationale:   `A container without a PIDs limit can create processes until the host runs out of them, so that a fork bomb in one container brings down every other container and the host with it.`,
+		auditDescription: `$> docker ps -q | xargs docker inspect --format '{{ .Name }}: Pid={{ .State.Pid }} PidsLimit={{ .HostConfig.PidsLimit }}'
+$> cat /proc/<pid>/cgroup
+$> cat /sys/fs/cgroup/<path>/pids.max (cgroup v2) or /sys/fs/cgroup/pids/<path>/pids.max (cgroup v1)
+Ensure that PidsLimit is greater than 0, and that pids.max is not 'max'.`,
+		remediation:  `Start containers with '--pids-limit', for example: $> docker run --pids-limit 100 <image>`,
+		impact:       `Containers that need more processes than their limit fail to create them.`,
+		defaultValue: `By default, the number of processes of a container is not limited.`,
+		references: []string{
+			"https://docs.docker.com/engine/reference/commandline/run/#options",
+			"https://www.kernel.org/doc/Documentation/cgroup-v1/pids.txt",
+		},
+	})
+}
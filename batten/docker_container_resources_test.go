@@ -0,0 +1,86 @@
+package batten
+
+import (
+	"io/ioutil"
+	"path"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestContainerResources(t *testing.T) {
+	server := newFakeDocker(t)
+	defer server.Stop()
+	server.inspect(
+		`{"Id": "a1", "Name": "/v2", "Config": {"Image": "nginx"}, "State": {"Running": true, "Pid": 2001}, "HostConfig": {"Memory": 268435456, "PidsLimit": 100}}`,
+		`{"Id": "b2", "Name": "/v1", "Config": {"Image": "redis"}, "State": {"Running": true, "Pid": 2002}, "HostConfig": {"CpuShares": 512, "OomKillDisable": true}}`,
+		`{"Id": "c3", "Name": "/remote", "Config": {"Image": "app"}, "State": {"Running": true, "Pid": 2003}, "HostConfig": {"NanoCpus": 500000000, "PidsLimit": -1}}`,
+		`{"Id": "d4", "Name": "/namespaced", "Config": {"Image": "api"}, "State": {"Running": true, "Pid": 2004}, "HostConfig": {"Memory": 268435456, "CpuShares": 512, "PidsLimit": 100}}`,
+	)
+	defer server.use(t)()
+
+	defer withProcesses(t, map[int]fakeProcess{
+		2001: {cmdline: "nginx"},
+		2002: {cmdline: "redis-server"},
+		2004: {cmdline: "api"},
+	})()
+	cgroups := map[int]string{
+		2001: "0::/system.slice/docker-a1.scope\n",
+		2002: "12:pids:/docker/b2\n6:memory:/docker/b2\n4:cpu,cpuacct:/docker/b2\n1:name=systemd:/docker/b2\n",
+		// as read from the cgroup namespace of a scan container; the cgroup
+		// of pids it resolves to does not have the process
+		2004: "12:pids:/../../docker/d4\n6:memory:/../../docker/d4\n4:cpu,cpuacct:/../../docker/d4\n",
+	}
+	for pid, cgroup := range cgroups {
+		if err := ioutil.WriteFile(path.Join(procRoot, strconv.Itoa(pid), "cgroup"), []byte(cgroup), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer withHostFiles(t, map[string]string{
+		// the memory limit was lifted from under the daemon
+		"/sys/fs/cgroup/system.slice/docker-a1.scope/memory.max": "max\n",
+		"/sys/fs/cgroup/system.slice/docker-a1.scope/cpu.max":    "max 100000\n",
+		"/sys/fs/cgroup/system.slice/docker-a1.scope/cpu.weight": "100\n",
+		"/sys/fs/cgroup/system.slice/docker-a1.scope/pids.max":   "100\n",
+		"/sys/fs/cgroup/memory/docker/b2/memory.limit_in_bytes":  "9223372036854771712\n",
+		"/sys/fs/cgroup/cpu,cpuacct/docker/b2/cpu.cfs_quota_us":  "-1\n",
+		"/sys/fs/cgroup/cpu,cpuacct/docker/b2/cpu.shares":        "512\n",
+		"/sys/fs/cgroup/pids/docker/b2/pids.max":                 "max\n",
+		"/sys/fs/cgroup/memory/docker/d4/cgroup.procs":           "2004\n",
+		"/sys/fs/cgroup/memory/docker/d4/memory.limit_in_bytes":  "268435456\n",
+		"/sys/fs/cgroup/cpu,cpuacct/docker/d4/cgroup.procs":      "2004\n",
+		"/sys/fs/cgroup/cpu,cpuacct/docker/d4/cpu.cfs_quota_us":  "-1\n",
+		"/sys/fs/cgroup/cpu,cpuacct/docker/d4/cpu.shares":        "1024\n",
+		"/sys/fs/cgroup/pids/docker/d4/cgroup.procs":             "2044\n",
+	})()
+
+	tests := []struct {
+		check    Check
+		expected []Finding
+	}{
+		{makeDockerContainerMemoryCheck(), []Finding{
+			{Subject: "v2 (nginx)", Detail: "is configured with a memory limit its cgroup does not enforce (memory.max is max)"},
+			{Subject: "v1 (redis)", Detail: "runs without a memory limit (memory.limit_in_bytes is 9223372036854771712)"},
+			{Subject: "v1 (redis)", Detail: "disables the OOM killer without a memory limit, so that it can exhaust the host's memory"},
+			{Subject: "remote (app)", Detail: "runs without a memory limit"},
+		}},
+		{makeDockerContainerCPUCheck(), []Finding{
+			{Subject: "v2 (nginx)", Detail: "runs without a CPU share or quota (cpu.max is 'max 100000' and cpu.weight is 100)"},
+			{Subject: "namespaced (api)", Detail: "is configured with a CPU share or quota its cgroup does not enforce (cpu.cfs_quota_us is -1 and cpu.shares is 1024)"},
+		}},
+		{makeDockerContainerPidsCheck(), []Finding{
+			{Subject: "v1 (redis)", Detail: "runs without a PIDs limit (pids.max is max)"},
+			{Subject: "remote (app)", Detail: "runs without a PIDs limit"},
+			{Subject: "namespaced (api)", Detail: "is configured with a PIDs limit that could not be verified (cgroup /../../docker/d4 not found on the host)"},
+		}},
+	}
+	for _, test := range tests {
+		succ, findings, err := test.check.(findingsCheck).AuditFindings()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if succ || !reflect.DeepEqual(findings, test.expected) {
+			t.Errorf("%s: got %v, expected %v", test.check.GetCheckDefinition().Identifier(), findings, test.expected)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package batten
+
+import "fmt"
+
+func (dc *DockerContainerRestartPolicyCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+func (dc *DockerContainerRestartPolicyCheck) AuditFindings() (bool, []Finding, error) {
+	return auditRunningContainers(func(c *containerInspect) []string {
+		var details []string
+		policy := c.HostConfig.RestartPolicy
+		switch {
+		case policy.Name == "always" || policy.Name == "unless-stopped":
+			details = append(details, fmt.Sprintf("restarts whenever it stops, without a limit ('--restart=%s')", policy.Name))
+		case policy.Name == "on-failure" && policy.MaximumRetryCount == 0:
+			details = append(details, "restarts on failure without a limit ('--restart=on-failure')")
+		case policy.Name == "on-failure" && policy.MaximumRetryCount > dc.maxRetries:
+			details = append(details, fmt.Sprintf("restarts on failure up to %d times, more than %d ('--restart=on-failure:%d')", policy.MaximumRetryCount, dc.maxRetries, policy.MaximumRetryCount))
+		}
+		if c.RestartCount > dc.maxRetries {
+			details = append(details, fmt.Sprintf("has restarted %d times", c.RestartCount))
+		}
+		return details
+	})
+}
+
+func (dc *DockerContainerRestartPolicyCheck) AuditCheck() (bool, error) {
+	succ, _, err := dc.AuditFindings()
+	return succ, err
+}
+
+type DockerContainerRestartPolicyCheck struct {
+	*CheckDefinitionImpl
+	DockerAPICheck
+	maxRetries int
+}
+
+func makeDockerContainerRestartPolicyCheck() Check {
+	return &DockerContainerRestartPolicyCheck{
+		maxRetries: 5,
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "CIS-Docker-Benchmark-5.14",
+			severity:    SeverityLow,
+			category:    `Container Runtime`,
+			name:        `Set the 'on-failure' container restart policy to 5`,
+			description: `Using the '--restart' flag in 'docker run' command you can specify a restart policy for how a container should or should not be restarted on exit. You should choose the 'on-failure' restart policy and limit the restart attempts to 5.`,
+			rationale:   `If you indefinitely keep trying to start the container, it could possibly lead to a denial of service on the host. It could be an easy way to do a distributed denial of service attack especially if you have many containers on the same host. Additionally, ignoring the exit status of the container and 'always' attempting to restart the container leads to non-investigation of the root cause behind containers getting terminated. If a container gets terminated, you should investigate on the reason behind it instead of just attempting to restart it indefinitely. Thus, it is recommended to use 'on-failure' restart policy and limit it to maximum of 5 restart attempts. A container that has already restarted more often than that is crash-looping, whatever its policy.`,
+			auditDescription: `$> docker ps --quiet | xargs docker inspect --format '{{ .Name }}: RestartPolicyName={{ .HostConfig.RestartPolicy.Name }} MaximumRetryCount={{ .HostConfig.RestartPolicy.MaximumRetryCount }} RestartCount={{ .RestartCount }}'
+If the above command returns 'RestartPolicyName=always' or 'unless-stopped', or 'on-failure' with a MaximumRetryCount of 0 or more than 5, then the system is not configured as desired. Ensure too that RestartCount is not more than 5.`,
+			remediation: `If a container is desired to be restarted on its own, then, for example, you could start the container as below:
+$> docker run --detach --restart=on-failure:5 nginx
+Investigate why containers that restarted many times keep failing.`,
+			impact:       `The container would attempt to restart only for 5 times.`,
+			defaultValue: `By default, containers are not configured with restart policies. Hence, containers do not attempt to restart of their own.`,
+			references: []string{
+				"https://docs.docker.com/engine/reference/run/#restart-policies---restart",
+			},
+		},
+	}
+}
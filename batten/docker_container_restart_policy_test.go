@@ -0,0 +1,48 @@
+package batten
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestContainerRestartAndHealth(t *testing.T) {
+	server := newFakeDocker(t)
+	defer server.Stop()
+	server.inspect(
+		`{"Id": "a1", "Name": "/web", "Config": {"Image": "nginx", "Healthcheck": {"Test": ["CMD-SHELL", "curl -f http://localhost/"]}},
+			"State": {"Health": {"Status": "healthy"}}, "HostConfig": {"RestartPolicy": {"Name": "on-failure", "MaximumRetryCount": 5}}}`,
+		`{"Id": "b2", "Name": "/worker", "RestartCount": 48, "Config": {"Image": "worker"},
+			"HostConfig": {"RestartPolicy": {"Name": "always"}}}`,
+		`{"Id": "c3", "Name": "/api", "Config": {"Image": "api", "Healthcheck": {"Test": ["CMD", "/healthz"]}},
+			"State": {"Health": {"Status": "unhealthy", "FailingStreak": 3}}, "HostConfig": {"RestartPolicy": {"Name": "on-failure", "MaximumRetryCount": 10}}}`,
+		`{"Id": "d4", "Name": "/db", "Config": {"Image": "postgres", "Healthcheck": {"Test": ["NONE"]}},
+			"HostConfig": {"RestartPolicy": {"Name": "on-failure"}}}`,
+	)
+	defer server.use(t)()
+
+	tests := []struct {
+		check    Check
+		expected []Finding
+	}{
+		{makeDockerContainerRestartPolicyCheck(), []Finding{
+			{Subject: "worker (worker)", Detail: "restarts whenever it stops, without a limit ('--restart=always')"},
+			{Subject: "worker (worker)", Detail: "has restarted 48 times"},
+			{Subject: "api (api)", Detail: "restarts on failure up to 10 times, more than 5 ('--restart=on-failure:10')"},
+			{Subject: "db (postgres)", Detail: "restarts on failure without a limit ('--restart=on-failure')"},
+		}},
+		{makeDockerContainerHealthcheckCheck(), []Finding{
+			{Subject: "worker (worker)", Detail: "has no health check, in its image or its container"},
+			{Subject: "api (api)", Detail: "is unhealthy, after 3 failed health checks in a row"},
+			{Subject: "db (postgres)", Detail: "disables the health check of its image with '--no-healthcheck'"},
+		}},
+	}
+	for _, test := range tests {
+		succ, findings, err := test.check.(findingsCheck).AuditFindings()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if succ || !reflect.DeepEqual(findings, test.expected) {
+			t.Errorf("%s: got %v, expected %v", test.check.GetCheckDefinition().Identifier(), findings, test.expected)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package batten
+
+func (dc *DockerContainerRuntimeSocketCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+func (dc *DockerContainerRuntimeSocketCheck) AuditFindings() (bool, []Finding, error) {
+	sockets := []string{hostPathOf(dockerSocket), hostPathOf(DefaultDockerSocket), DefaultContainerdSocket}
+	return auditRunningContainers(func(c *containerInspect) []string {
+		var details []string
+		for _, m := range c.Mounts {
+			if m.Type != "bind" {
+				continue
+			}
+			source := hostPathOf(m.Source)
+			for _, socket := range sockets {
+				if underPath(socket, source) {
+					details = append(details, "mounts the socket "+socket+", and so controls the host: "+m.String())
+					break
+				}
+			}
+		}
+		return details
+	})
+}
+
+func (dc *DockerContainerRuntimeSocketCheck) AuditCheck() (bool, error) {
+	succ, _, err := dc.AuditFindings()
+	return succ, err
+}
+
+type DockerContainerRuntimeSocketCheck struct {
+	*CheckDefinitionImpl
+	DockerAPICheck
+}
+
+func makeDockerContainerRuntimeSocketCheck() Check {
+	return &DockerContainerRuntimeSocketCheck{
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "Batten-Container-Runtime-Socket",
+			severity:    SeverityHigh,
+			category:    `Container Runtime`,
+			name:        `Do not mount the Docker or containerd socket inside containers`,
+			description: `Verify that no running container bind-mounts the socket of the Docker daemon or of containerd, or a directory it is in such as /run or the host's root.`,
+			rationale:   `Whoever can talk to the Docker or containerd socket can start a privileged container with the host's root filesystem mounted, so a container with the socket mounted is root on the host, whatever its own user, capabilities or profiles, and mounting it read-only does not prevent it.`,
+			auditDescription: `$> docker ps -q | xargs docker inspect --format '{{ .Name }}: {{ range .Mounts }}{{ .Source }}:{{ .Destination }} {{ end }}' | grep -e docker.sock -e containerd.sock -e ':/run' -e ':/var/run'
+Ensure that no container mounts the sockets, or the directories they are in.`,
+			remediation:  `Do not mount the Docker or containerd socket into containers. Tools that need the Docker API should go through an authorization plugin or a proxy that only allows what they need.`,
+			defaultValue: `By default, containers do not mount the Docker socket.`,
+			references: []string{
+				"https://docs.docker.com/engine/security/#docker-daemon-attack-surface",
+			},
+		},
+	}
+}
@@ -0,0 +1,50 @@
+package batten
+
+import "fmt"
+
+func (dc *DockerContainerUnconfinedCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+func (dc *DockerContainerUnconfinedCheck) AuditFindings() (bool, []Finding, error) {
+	return auditRunningContainers(func(c *containerInspect) []string {
+		var details []string
+		for _, profile := range []struct{ option, name string }{{"seccomp", "a seccomp"}, {"apparmor", "an AppArmor"}} {
+			if value, _ := c.SecurityOption(profile.option); value == "unconfined" {
+				details = append(details, fmt.Sprintf("runs without %s profile, with '--security-opt=%s=unconfined'", profile.name, profile.option))
+			}
+		}
+		return details
+	})
+}
+
+func (dc *DockerContainerUnconfinedCheck) AuditCheck() (bool, error) {
+	succ, _, err := dc.AuditFindings()
+	return succ, err
+}
+
+type DockerContainerUnconfinedCheck struct {
+	*CheckDefinitionImpl
+	DockerAPICheck
+}
+
+func makeDockerContainerUnconfinedCheck() Check {
+	return &DockerContainerUnconfinedCheck{
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "Batten-Container-Unconfined",
+			severity:    SeverityHigh,
+			category:    `Container Runtime`,
+			name:        `Do not disable the seccomp or AppArmor profile of containers`,
+			description: `Verify that no running container was started with '--security-opt=seccomp=unconfined' or '--security-opt=apparmor=unconfined'.`,
+			rationale:   `The default seccomp profile blocks system calls containers do not need and that have been used to escape them, and the default AppArmor profile denies writes to sensitive parts of /proc and /sys. An unconfined container has neither protection.`,
+			auditDescription: `$> docker ps -q | xargs docker inspect --format '{{ .Name }}: SecurityOpt={{ .HostConfig.SecurityOpt }}'
+Ensure that no container has 'seccomp=unconfined' or 'apparmor=unconfined' in its SecurityOpt.`,
+			remediation:  `Do not start containers with an unconfined profile. If a container needs more than the default profile allows, give it a custom profile based on the default one, with '--security-opt=seccomp=<profile.json>' or '--security-opt=apparmor=<profile>'.`,
+			defaultValue: `By default, containers run with Docker's default seccomp profile, and with the 'docker-default' AppArmor profile on hosts with AppArmor.`,
+			references: []string{
+				"https://docs.docker.com/engine/security/seccomp/",
+				"https://docs.docker.com/engine/security/apparmor/",
+			},
+		},
+	}
+}
@@ -9,7 +9,7 @@ func (dc *DockerContainerUserCheck) GetCheckDefinition() CheckDefinition {
 // list all running containers, and ensure they are all running as root
 func (dc *DockerContainerUserCheck) AuditCheck() (bool, error) {
 
-	client, err := docker.NewClient(DockerUnixSocket)
+	client, err := getDockerAPIConnection()
 
 	if err != nil {
 		// TODO: log error message
@@ -40,6 +40,7 @@ func (dc *DockerContainerUserCheck) AuditCheck() (bool, error) {
 
 type DockerContainerUserCheck struct {
 	*CheckDefinitionImpl
+	DockerAPICheck
 	// TODO: allow the user to specify a policy of containers they are ok with running as root.
 }
 
@@ -0,0 +1,98 @@
+package batten
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// mappedRoot returns the host id that id 0 is mapped to by a process's
+// /proc/<pid>/uid_map, whose lines are `inside outside count`.
+func mappedRoot(data []byte) (int64, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		inside, err1 := strconv.ParseInt(fields[0], 10, 64)
+		outside, err2 := strconv.ParseInt(fields[1], 10, 64)
+		count, err3 := strconv.ParseInt(fields[2], 10, 64)
+		if err1 == nil && err2 == nil && err3 == nil && inside == 0 && count > 0 {
+			return outside, true
+		}
+	}
+	return 0, false
+}
+
+func (dc *DockerContainerUsernsCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+func (dc *DockerContainerUsernsCheck) AuditFindings() (bool, []Finding, error) {
+	containers, err := inspectRunningContainers()
+	if err != nil {
+		return false, nil, err
+	}
+
+	var findings []Finding
+	for _, c := range containers {
+		// containers started with '--userns=host' are reported, or allowed
+		// by the policy, by Batten-Container-Host-Userns
+		if c.HostConfig.UsernsMode == "host" || c.State.Pid <= 0 {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(path.Join(procRoot, strconv.Itoa(c.State.Pid), "uid_map"))
+		if os.IsNotExist(err) {
+			// the container does not run on this host
+			continue
+		} else if err != nil {
+			return false, nil, err
+		}
+		if root, ok := mappedRoot(data); ok && root == 0 {
+			findings = append(findings, Finding{
+				Subject: c.Subject(),
+				Detail:  fmt.Sprintf("root in the container is root on the host (pid %d)", c.State.Pid),
+			})
+		}
+	}
+	return len(findings) == 0, findings, nil
+}
+
+func (dc *DockerContainerUsernsCheck) AuditCheck() (bool, error) {
+	succ, _, err := dc.AuditFindings()
+	return succ, err
+}
+
+type DockerContainerUsernsCheck struct {
+	*CheckDefinitionImpl
+}
+
+func makeDockerContainerUsernsCheck() Check {
+	return &DockerContainerUsernsCheck{
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "Batten-Container-Userns",
+			level:       2,
+			severity:    SeverityHigh,
+			category:    `Container Runtime`,
+			name:        `Verify that root in containers is not root on the host`,
+			description: `Verify, from the user namespace of every running container, that root in the container is mapped to an unprivileged user of the host. Containers started with '--userns=host' are left to Batten-Container-Host-Userns.`,
+			rationale:   `The daemon's configuration says how containers should be started, but only the user namespace a container actually runs in says whether its root is root on the host.`,
+			auditDescription: `$> docker ps -q | xargs docker inspect --format '{{ .Name }}: Pid={{ .State.Pid }}'
+$> cat /proc/<pid>/uid_map
+Ensure that the line of uid_map for id 0 maps it to an id other than 0.`,
+			remediation:  `Enable user namespace remapping on the daemon.`,
+			defaultValue: `By default, user namespaces are not remapped, and root in containers is root on the host.`,
+			references: []string{
+				"https://docs.docker.com/engine/security/userns-remap/",
+				"http://man7.org/linux/man-pages/man7/user_namespaces.7.html",
+			},
+		},
+	}
+}
@@ -0,0 +1,59 @@
+package batten
+
+import (
+	"io/ioutil"
+	"path"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestContainerUserns(t *testing.T) {
+	server := newFakeDocker(t)
+	defer server.Stop()
+	server.inspect(
+		`{"Id": "a1", "Name": "/remapped", "Config": {"Image": "nginx"}, "State": {"Running": true, "Pid": 2001}}`,
+		`{"Id": "b2", "Name": "/hostns", "State": {"Running": true, "Pid": 2002}, "HostConfig": {"UsernsMode": "host"}}`,
+		`{"Id": "c3", "Name": "/elsewhere", "State": {"Running": true, "Pid": 2003}}`,
+	)
+	defer server.use(t)()
+
+	defer withProcesses(t, map[int]fakeProcess{
+		2001: {cmdline: "nginx"},
+		2002: {cmdline: "nginx"},
+	})()
+	defer withHostFiles(t, nil)()
+	uidMaps := map[int]string{
+		2001: "         0     165536      65536\n",
+		2002: "         0          0 4294967295\n",
+	}
+	for pid, uidMap := range uidMaps {
+		if err := ioutil.WriteFile(path.Join(procRoot, strconv.Itoa(pid), "uid_map"), []byte(uidMap), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	check := makeDockerContainerUsernsCheck().(*DockerContainerUsernsCheck)
+	succ, findings, err := check.AuditFindings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the container in the host's user namespace is left to
+	// Batten-Container-Host-Userns
+	if !succ || len(findings) > 0 {
+		t.Errorf("unexpected findings %v", findings)
+	}
+
+	// a container whose root is not remapped
+	if err := ioutil.WriteFile(path.Join(procRoot, "2001", "uid_map"), []byte(uidMaps[2002]), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, findings, err = check.AuditFindings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Finding{{Subject: "remapped (nginx)", Detail: "root in the container is root on the host (pid 2001)"}}
+	if !reflect.DeepEqual(findings, expected) {
+		t.Errorf("got %v, expected %v", findings, expected)
+	}
+}
@@ -0,0 +1,112 @@
+package batten
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+func (dc *DockerDaemonConfigDriftCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+// daemonDrift compares the options of the running daemon's command line
+// with those of the command line it is configured to be started with.
+func daemonDrift(running []string, configured []string) []Finding {
+	runningOptions := parseDaemonArgs(running)
+	configuredOptions := parseDaemonArgs(configured)
+
+	var names []string
+	for name := range runningOptions {
+		names = append(names, name)
+	}
+	for name := range configuredOptions {
+		if _, ok := runningOptions[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var findings []Finding
+	for _, name := range names {
+		r, c := sortedCopy(runningOptions[name]), sortedCopy(configuredOptions[name])
+		if strings.Join(r, "\x00") == strings.Join(c, "\x00") {
+			continue
+		}
+		findings = append(findings, Finding{
+			Subject: "--" + name,
+			Detail:  fmt.Sprintf("running with %s, configured with %s", describeValues(r), describeValues(c)),
+		})
+	}
+	return findings
+}
+
+func sortedCopy(values []string) []string {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+func describeValues(values []string) string {
+	if len(values) == 0 {
+		return "nothing"
+	}
+	return "'" + strings.Join(values, "', '") + "'"
+}
+
+func (dc *DockerDaemonConfigDriftCheck) AuditFindings() (bool, []Finding, error) {
+	daemon, err := findDaemon(dc.dockerPidFile)
+	if err != nil {
+		return false, nil, err
+	}
+	if daemon == nil || daemon.Rootless || daemon.Root != "" {
+		// nothing is running that could have drifted, or the daemon is
+		// not started by the host's service manager
+		return true, nil, nil
+	}
+
+	configured, err := readConfiguredDaemon()
+	if err != nil {
+		return false, nil, err
+	}
+	if configured == nil {
+		return false, nil, fmt.Errorf("could not find how the Docker daemon is configured to start")
+	}
+
+	findings := daemonDrift(daemon.Args, configured.Args)
+	return len(findings) == 0, findings, nil
+}
+
+func (dc *DockerDaemonConfigDriftCheck) AuditCheck() (bool, error) {
+	succ, _, err := dc.AuditFindings()
+	return succ, err
+}
+
+type DockerDaemonConfigDriftCheck struct {
+	*CheckDefinitionImpl
+	DaemonCheck
+	dockerPidFile string
+}
+
+func makeDockerDaemonConfigDriftCheck() Check {
+	return &DockerDaemonConfigDriftCheck{
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "Batten-Daemon-Config-Drift",
+			category:    `Docker daemon configuration`,
+			name:        `Verify that the running Docker daemon matches its configuration`,
+			description: `Verify that the Docker daemon runs with the options it is configured to be started with, in 'docker.service' and its drop-ins or in the distribution's environment files such as '/etc/sysconfig/docker' and '/etc/default/docker'.`,
+			rationale:   `A daemon that was started by hand, or whose configuration was changed without restarting it, runs with options that nobody reviewed and that are lost on the next restart. Options such as '--insecure-registry' or '-H tcp://0.0.0.0:2375' are easily added this way and go unnoticed in the configuration files.`,
+			auditDescription: `Compare the command line of the running daemon with the one it is configured to be started with:
+
+ps -ef | grep dockerd
+systemctl cat docker.service`,
+			remediation: `Restart the Docker daemon through its service manager so that it runs with its configuration, or move the options it needs into its configuration:
+
+#> systemctl restart docker`,
+			defaultValue: `The daemon runs with its configured options when started by its service manager.`,
+			references: []string{
+				"https://docs.docker.com/config/daemon/systemd/",
+			},
+		},
+	}
+}
@@ -19,6 +19,7 @@ func makeDockerDevToolsCheck() Check {
 	return &DockerDevToolsCheck{
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:       "CIS-Docker-Benchmark-1.3",
+			severity:         SeverityLow,
 			name:             "Do not use development tools in production",
 			category:         "Host Configuration",
 			impact:           "None",
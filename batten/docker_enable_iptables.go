@@ -1,44 +1,21 @@
 package batten
 
-import (
-	"errors"
-	"strings"
-)
-
 func (dc *DockerEnableIptablesCheck) GetCheckDefinition() CheckDefinition {
 	return dc
 }
 
-func (dc *DockerEnableIptablesCheck) lookForIptables(argv string) (bool, error) {
-	if strings.Contains(argv, "--iptables=false") {
-		return true, nil
-	}
-	if strings.Contains(argv, "--iptables false") {
-		return true, nil
-	}
-	if strings.Contains(argv, "--iptables=") {
-		return false, nil
-	}
-
-	return true, nil
-}
-
 func (dc *DockerEnableIptablesCheck) AuditCheck() (bool, error) {
-	succ, args, err := readDockerDaemonArgs(dc.dockerPidFile)
-
+	options, err := readDaemonOptions(dc.dockerPidFile, "iptables")
 	if err != nil {
 		return false, err
 	}
 
-	if succ {
-		argv := strings.Join(args, " ")
-		return dc.lookForIptables(argv)
-	}
-	return false, errors.New("Docker daemon not running")
+	return options.IPTables, nil
 }
 
 type DockerEnableIptablesCheck struct {
 	*CheckDefinitionImpl
+	DaemonCheck
 	dockerPidFile string
 }
 
@@ -61,6 +38,5 @@ $> docker -d --iptables=false`,
 				"http://docs.docker.com/articles/networking/#communication-between-containers",
 			},
 		},
-		dockerPidFile: "/var/run/docker.pid",
 	}
 }
@@ -0,0 +1,43 @@
+package batten
+
+func (dc *DockerExperimentalCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+func (dc *DockerExperimentalCheck) AuditCheck() (bool, error) {
+	options, err := readDaemonOptions(dc.dockerPidFile, "experimental")
+	if err != nil {
+		return false, err
+	}
+
+	return !options.Experimental, nil
+}
+
+type DockerExperimentalCheck struct {
+	*CheckDefinitionImpl
+	DaemonCheck
+	dockerPidFile string
+}
+
+func makeDockerExperimentalCheck() Check {
+	return &DockerExperimentalCheck{
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "Batten-Daemon-Experimental",
+			severity:    SeverityLow,
+			category:    `Docker daemon configuration`,
+			name:        `Do not enable experimental features in production`,
+			description: `Do not run a production Docker daemon with '--experimental'.`,
+			rationale:   `Experimental features are not finished, may change or disappear between releases, and have not had the scrutiny the rest of the daemon has. They add attack surface that is not needed in production.`,
+			auditDescription: `$> docker version --format '{{ .Server.Experimental }}'
+$> ps -ef | grep dockerd
+$> cat /etc/docker/daemon.json
+Ensure that '--experimental' is not given, and that "experimental" is not true in daemon.json.`,
+			remediation:  `Remove '--experimental' from the daemon's command line, and "experimental": true from /etc/docker/daemon.json.`,
+			impact:       `Experimental features can no longer be used.`,
+			defaultValue: `By default, experimental features are disabled.`,
+			references: []string{
+				"https://docs.docker.com/engine/reference/commandline/dockerd/",
+			},
+		},
+	}
+}
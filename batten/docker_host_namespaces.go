@@ -0,0 +1,208 @@
+package batten
+
+import "strings"
+
+// hostNamespaces say, for each namespace a container can share with the
+// host, how the container shares it, or "" if it does not.
+var hostNamespaces = map[string]func(c *containerInspect) string{
+	"network": func(c *containerInspect) string {
+		if c.HostConfig.NetworkMode == "host" {
+			return "shares the host's network namespace with '--network=host'"
+		}
+		return ""
+	},
+	"pid": func(c *containerInspect) string {
+		if c.HostConfig.PidMode == "host" {
+			return "shares the host's process namespace with '--pid=host'"
+		}
+		return ""
+	},
+	"ipc": func(c *containerInspect) string {
+		if c.HostConfig.IpcMode == "host" {
+			return "shares the host's IPC namespace with '--ipc=host'"
+		}
+		if strings.HasPrefix(c.HostConfig.IpcMode, "container:") {
+			return "shares the IPC namespace of another container with '--ipc=" + c.HostConfig.IpcMode + "'"
+		}
+		return ""
+	},
+	"uts": func(c *containerInspect) string {
+		if c.HostConfig.UTSMode == "host" {
+			return "shares the host's UTS namespace with '--uts=host'"
+		}
+		return ""
+	},
+	"userns": func(c *containerInspect) string {
+		if c.HostConfig.UsernsMode == "host" {
+			return "shares the host's user namespace with '--userns=host'"
+		}
+		return ""
+	},
+	"cgroup": func(c *containerInspect) string {
+		if c.HostConfig.CgroupnsMode == "host" {
+			return "shares the host's cgroup namespace with '--cgroupns=host'"
+		}
+		return ""
+	},
+}
+
+func (dc *DockerHostNamespaceCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+// allowed tells whether the policy lets the container named `name`
+// running `image` share the check's namespace.
+func (dc *DockerHostNamespaceCheck) allowed(image string, name string) bool {
+	for i := range dc.policy.Containers {
+		rule := &dc.policy.Containers[i]
+		if rule.matches(image, name) && stringInSlice(dc.namespace, rule.Allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+func (dc *DockerHostNamespaceCheck) usePolicy(policy *Policy) {
+	if policy.Namespaces != nil {
+		dc.policy = *policy.Namespaces
+	}
+}
+
+func (dc *DockerHostNamespaceCheck) AuditFindings() (bool, []Finding, error) {
+	shared := hostNamespaces[dc.namespace]
+	return auditRunningContainers(func(c *containerInspect) []string {
+		detail := shared(c)
+		if detail == "" || dc.allowed(c.Config.Image, c.Name) {
+			return nil
+		}
+		return []string{detail}
+	})
+}
+
+func (dc *DockerHostNamespaceCheck) AuditCheck() (bool, error) {
+	succ, _, err := dc.AuditFindings()
+	return succ, err
+}
+
+type DockerHostNamespaceCheck struct {
+	*CheckDefinitionImpl
+	DockerAPICheck
+	namespace string
+	policy    NamespacePolicy
+}
+
+func newDockerHostNamespaceCheck(namespace string, definition *CheckDefinitionImpl) Check {
+	definition.category = `Container Runtime`
+	definition.references = append(definition.references,
+		"http://man7.org/linux/man-pages/man7/namespaces.7.html")
+	return &DockerHostNamespaceCheck{
+		CheckDefinitionImpl: definition,
+		namespace:           namespace,
+	}
+}
+
+func makeDockerHostNetworkCheck() Check {
+	return newDockerHostNamespaceCheck("network", &CheckDefinitionImpl{
+		identifier:  "CIS-Docker-Benchmark-5.9",
+		severity:    SeverityHigh,
+		name:        `Do not use host network mode on container`,
+		description: `The networking mode on a container when set to '--net=host', skips placing the container inside separate network stack. In essence, this choice tells Docker to not containerize the container's networking. This would network-wise mean that the container lives "outside" in the main Docker host and has full access to its network interfaces.`,
+		rationale:   `This is potentially dangerous. It allows the container process to open low-numbered ports like any other root process. It also allows the container to access network services like D-bus on the Docker host. Thus, a container process can potentially do unexpected things such as shutting down the Docker host. You should not use this option.`,
+		auditDescription: `$> docker ps -q | xargs docker inspect --format '{{ .Name }}: NetworkMode={{ .HostConfig.NetworkMode }}'
+If the above command returns 'NetworkMode=host', it means that '--net=host' option was passed when the container was started. Containers allowed to by the 'namespaces' section of the policy are not reported.`,
+		remediation:  `Do not pass '--net=host' option when starting the container.`,
+		impact:       `None`,
+		defaultValue: `By default, container connects to Docker bridge.`,
+		references: []string{
+			"https://docs.docker.com/engine/reference/run/#network-settings",
+		},
+	})
+}
+
+func makeDockerHostPidCheck() Check {
+	return newDockerHostNamespaceCheck("pid", &CheckDefinitionImpl{
+		identifier:  "CIS-Docker-Benchmark-5.15",
+		severity:    SeverityHigh,
+		name:        `Do not share the host's process namespace`,
+		description: `Process ID (PID) namespaces isolate the process ID number space, meaning that processes in different PID namespaces can have the same PID. This is process level isolation between containers and the host.`,
+		rationale:   `PID namespace provides separation of processes. The PID Namespace removes the view of the system processes, and allows process ids to be reused including PID 1. If the host's PID namespace is shared with the container, it would basically allow processes within the container to see all of the processes on the host system. This breaks the benefit of process level isolation between the host and the containers. Someone having access to the container can eventually know all the processes running on the host system and can even kill the host system processes from within the container. This can be catastrophic. Hence, do not share the host's process namespace with the containers.`,
+		auditDescription: `$> docker ps -q | xargs docker inspect --format '{{ .Name }}: PidMode={{ .HostConfig.PidMode }}'
+If the above command returns 'PidMode=host', it means the host PID namespace is shared with the container. Containers allowed to by the 'namespaces' section of the policy are not reported.`,
+		remediation:  `Do not start a container with '--pid=host' argument.`,
+		impact:       `Container processes cannot see the processes on the host system. In certain cases, you want your container to share the host's process namespace. For example, you could build a container with debugging tools like strace or gdb, but want to use these tools when debugging processes within the container. If this is desired, then share only one (or needed) host process by using the '-p' switch.`,
+		defaultValue: `By default, all containers have the PID namespace enabled and the host's process namespace is not shared with the containers.`,
+		references: []string{
+			"https://docs.docker.com/engine/reference/run/#pid-settings---pid",
+			"http://man7.org/linux/man-pages/man7/pid_namespaces.7.html",
+		},
+	})
+}
+
+func makeDockerHostIpcCheck() Check {
+	return newDockerHostNamespaceCheck("ipc", &CheckDefinitionImpl{
+		identifier:  "CIS-Docker-Benchmark-5.16",
+		name:        `Do not share the host's IPC namespace`,
+		description: `IPC (POSIX/SysV IPC) namespace provides separation of named shared memory segments, semaphores and message queues. IPC namespace on the host thus should not be shared with the containers and should remain isolated.`,
+		rationale:   `IPC namespace provides separation of IPC between the host and containers. If the host's IPC namespace is shared with the container, it would basically allow processes within the container to see all of the IPC on the host system. This breaks the benefit of IPC level isolation between the host and the containers. Someone having access to the container can eventually manipulate the host IPC. This can be catastrophic. Hence, do not share the host's IPC namespace with the containers. Sharing the IPC namespace of another container likewise lets either container tamper with the other's shared memory.`,
+		auditDescription: `$> docker ps -q | xargs docker inspect --format '{{ .Name }}: IpcMode={{ .HostConfig.IpcMode }}'
+If the above command returns 'IpcMode=host', it means the host IPC namespace is shared with the container, and 'IpcMode=container:<id>' that the IPC namespace of another container is. Containers allowed to by the 'namespaces' section of the policy are not reported.`,
+		remediation:  `Do not start a container with '--ipc=host' or '--ipc=container:<id>' argument.`,
+		impact:       `Shared memory segments are used in order to accelerate inter-process communication. It is commonly used by high-performance applications. If such applications are containerized into multiple containers, you might need to share the IPC namespace of the containers to achieve high performance. In such cases, you should still be sharing container specific IPC namespaces only and not the host IPC namespace.`,
+		defaultValue: `By default, all containers have the IPC namespace enabled and host IPC namespace is not shared with any container.`,
+		references: []string{
+			"https://docs.docker.com/engine/reference/run/#ipc-settings---ipc",
+		},
+	})
+}
+
+func makeDockerHostUtsCheck() Check {
+	return newDockerHostNamespaceCheck("uts", &CheckDefinitionImpl{
+		identifier:  "Batten-Container-Host-UTS",
+		severity:    SeverityLow,
+		name:        `Do not share the host's UTS namespace`,
+		description: `Verify that no running container shares the host's UTS namespace, with '--uts=host'.`,
+		rationale:   `The UTS namespace isolates the hostname and the NIS domain name. A container sharing the host's UTS namespace can change the hostname of the host.`,
+		auditDescription: `$> docker ps -q | xargs docker inspect --format '{{ .Name }}: UTSMode={{ .HostConfig.UTSMode }}'
+Ensure that no container has UTSMode 'host', unless allowed to by the 'namespaces' section of the policy.`,
+		remediation:  `Do not start containers with '--uts=host'.`,
+		defaultValue: `By default, every container has its own UTS namespace.`,
+		references: []string{
+			"https://docs.docker.com/engine/reference/run/#uts-settings---uts",
+		},
+	})
+}
+
+func makeDockerHostUsernsCheck() Check {
+	return newDockerHostNamespaceCheck("userns", &CheckDefinitionImpl{
+		identifier:  "Batten-Container-Host-Userns",
+		severity:    SeverityHigh,
+		name:        `Do not share the host's user namespace`,
+		description: `Verify that no running container opts out of user namespace remapping with '--userns=host'.`,
+		rationale:   `A container started with '--userns=host' runs in the host's user namespace, so that root in it is root on the host even when the daemon remaps the user namespaces of the other containers.`,
+		auditDescription: `$> docker ps -q | xargs docker inspect --format '{{ .Name }}: UsernsMode={{ .HostConfig.UsernsMode }}'
+Ensure that no container has UsernsMode 'host', unless allowed to by the 'namespaces' section of the policy.`,
+		remediation:  `Do not start containers with '--userns=host'.`,
+		defaultValue: `By default, containers run in the daemon's user namespace remapping, if it has one.`,
+		references: []string{
+			"https://docs.docker.com/engine/security/userns-remap/#disable-namespace-remapping-for-a-container",
+		},
+	})
+}
+
+func makeDockerHostCgroupnsCheck() Check {
+	return newDockerHostNamespaceCheck("cgroup", &CheckDefinitionImpl{
+		identifier:  "Batten-Container-Host-Cgroupns",
+		name:        `Do not share the host's cgroup namespace`,
+		description: `Verify that no running container shares the host's cgroup namespace, with '--cgroupns=host' or, on hosts with cgroup v1, by default.`,
+		rationale:   `A container in the host's cgroup namespace sees the host's cgroup hierarchy and where it sits in it, which tells about the other containers and services of the host and makes escapes through cgroup release agents easier.`,
+		auditDescription: `$> docker ps -q | xargs docker inspect --format '{{ .Name }}: CgroupnsMode={{ .HostConfig.CgroupnsMode }}'
+Ensure that no container has CgroupnsMode 'host', unless allowed to by the 'namespaces' section of the policy.`,
+		remediation:  `Start containers with '--cgroupns=private', or set "default-cgroupns-mode": "private" in /etc/docker/daemon.json.`,
+		impact:       `Containers that manage the cgroups of the host, such as some monitoring agents, need to be allowed by the policy.`,
+		defaultValue: `By default, containers have their own cgroup namespace on hosts with cgroup v2, and share the host's on hosts with cgroup v1.`,
+		references: []string{
+			"https://docs.docker.com/engine/reference/commandline/run/#options",
+			"http://man7.org/linux/man-pages/man7/cgroup_namespaces.7.html",
+		},
+	})
+}
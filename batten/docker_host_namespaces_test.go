@@ -0,0 +1,55 @@
+package batten
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHostNamespaces(t *testing.T) {
+	server := newFakeDocker(t)
+	defer server.Stop()
+	server.inspect(
+		`{"Id": "a1", "Name": "/web", "Config": {"Image": "nginx"}, "HostConfig": {"NetworkMode": "bridge", "IpcMode": "private", "CgroupnsMode": "private"}}`,
+		`{"Id": "b2", "Name": "/node-exporter", "Config": {"Image": "prom/node-exporter:v1"}, "HostConfig": {"NetworkMode": "host", "PidMode": "host"}}`,
+		`{"Id": "c3", "Name": "/debug", "Config": {"Image": "busybox"}, "HostConfig": {"NetworkMode": "host", "PidMode": "host", "IpcMode": "container:a1", "UTSMode": "host", "UsernsMode": "host", "CgroupnsMode": "host"}}`,
+	)
+	defer server.use(t)()
+
+	policy := &Policy{Namespaces: &NamespacePolicy{Containers: []NamespaceRule{
+		{ContainerPattern: ContainerPattern{Image: "prom/node-exporter:*"}, Allowed: []string{"network"}},
+	}}}
+	tests := []struct {
+		check    Check
+		expected []Finding
+	}{
+		{makeDockerHostNetworkCheck(), []Finding{
+			{Subject: "debug (busybox)", Detail: "shares the host's network namespace with '--network=host'"},
+		}},
+		{makeDockerHostPidCheck(), []Finding{
+			{Subject: "node-exporter (prom/node-exporter:v1)", Detail: "shares the host's process namespace with '--pid=host'"},
+			{Subject: "debug (busybox)", Detail: "shares the host's process namespace with '--pid=host'"},
+		}},
+		{makeDockerHostIpcCheck(), []Finding{
+			{Subject: "debug (busybox)", Detail: "shares the IPC namespace of another container with '--ipc=container:a1'"},
+		}},
+		{makeDockerHostUtsCheck(), []Finding{
+			{Subject: "debug (busybox)", Detail: "shares the host's UTS namespace with '--uts=host'"},
+		}},
+		{makeDockerHostUsernsCheck(), []Finding{
+			{Subject: "debug (busybox)", Detail: "shares the host's user namespace with '--userns=host'"},
+		}},
+		{makeDockerHostCgroupnsCheck(), []Finding{
+			{Subject: "debug (busybox)", Detail: "shares the host's cgroup namespace with '--cgroupns=host'"},
+		}},
+	}
+	for _, test := range tests {
+		test.check.(policyCheck).usePolicy(policy)
+		succ, findings, err := test.check.(findingsCheck).AuditFindings()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if succ || !reflect.DeepEqual(findings, test.expected) {
+			t.Errorf("%s: got %v, expected %v", test.check.GetCheckDefinition().Identifier(), findings, test.expected)
+		}
+	}
+}
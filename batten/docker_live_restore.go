@@ -0,0 +1,46 @@
+package batten
+
+func (dc *DockerLiveRestoreCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+func (dc *DockerLiveRestoreCheck) AuditCheck() (bool, error) {
+	options, err := readDaemonOptions(dc.dockerPidFile, "live-restore")
+	if err != nil {
+		return false, err
+	}
+
+	return options.LiveRestore, nil
+}
+
+type DockerLiveRestoreCheck struct {
+	*CheckDefinitionImpl
+	DaemonCheck
+	dockerPidFile string
+}
+
+func makeDockerLiveRestoreCheck() Check {
+	return &DockerLiveRestoreCheck{
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "Batten-Daemon-Live-Restore",
+			severity:    SeverityLow,
+			category:    `Docker daemon configuration`,
+			name:        `Enable live restore`,
+			description: `Run the Docker daemon with '--live-restore', so that containers keep running while the daemon is stopped or restarted.`,
+			rationale:   `Security updates of the Docker daemon require restarting it. Without live restore, every container on the host is stopped when the daemon is, which makes patching the daemon costly and so tends to delay it. With live restore, the daemon can be updated without any downtime of the containers.`,
+			auditDescription: `$> ps -ef | grep dockerd
+$> cat /etc/docker/daemon.json
+Ensure that '--live-restore' is given, or that "live-restore" is true in daemon.json.`,
+			remediation: `Start the daemon with live restore:
+
+$> dockerd --live-restore
+
+or set "live-restore": true in /etc/docker/daemon.json and reload the daemon's configuration.`,
+			impact:       `Live restore is not available to daemons running in swarm mode.`,
+			defaultValue: `By default, live restore is disabled.`,
+			references: []string{
+				"https://docs.docker.com/config/containers/live-restore/",
+			},
+		},
+	}
+}
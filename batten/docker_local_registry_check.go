@@ -1,39 +1,21 @@
 package batten
 
-import (
-	"errors"
-	"strings"
-)
-
 func (dc *DockerLocalRegistryCheck) GetCheckDefinition() CheckDefinition {
 	return dc
 }
 
-func (dc *DockerLocalRegistryCheck) lookForRegistry(argv string) (bool, error) {
-
-	if strings.Contains(argv, "--registry-mirror=") {
-		return true, nil
-	}
-
-	return false, nil
-}
 func (dc *DockerLocalRegistryCheck) AuditCheck() (bool, error) {
-
-	succ, args, err := readDockerDaemonArgs(dc.dockerPidFile)
-
+	options, err := readDaemonOptions(dc.dockerPidFile, "registry-mirror")
 	if err != nil {
 		return false, err
 	}
 
-	if succ {
-		argv := strings.Join(args, " ")
-		return dc.lookForRegistry(argv)
-	}
-	return false, errors.New("Docker daemon not running")
+	return len(options.RegistryMirrors) > 0, nil
 }
 
 type DockerLocalRegistryCheck struct {
 	*CheckDefinitionImpl
+	DaemonCheck
 	// TODO: make configurable
 	dockerPidFile string
 }
@@ -42,6 +24,8 @@ func makeDockerLocalRegistryCheck() Check {
 	return &DockerLocalRegistryCheck{
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:  "CIS-Docker-Benchmark-2.6",
+			level:       2,
+			severity:    SeverityLow,
 			category:    `Docker daemon configuration`,
 			name:        `Setup a local registry mirror`,
 			description: `The local registry mirror is serves the images from its own storage.`,
@@ -63,7 +47,5 @@ $> docker --registry-mirror=https://10.0.0.2:5000 -d`,
 				"http://docs.docker.com/articles/registry_mirror/",
 			},
 		},
-
-		dockerPidFile: "/var/run/docker.pid",
 	}
 }
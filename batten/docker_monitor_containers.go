@@ -17,6 +17,7 @@ func makeDockerMonitorContainers() Check {
 	return &DockerMonitorContainers{
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:  "CIS-Docker-Benchmark-6.2",
+			severity:    SeverityLow,
 			category:    "Docker Security Operations",
 			name:        `Monitor Docker containers usage, performance and metering`,
 			description: `Containers might run services that are critical for your business. Monitoring their usage, performance and metering would be of paramount importance.`,
@@ -1,17 +1,13 @@
 package batten
 
-import (
-	"strings"
-
-	docker "github.com/fsouza/go-dockerclient"
-)
+import "strings"
 
 func (dc *DockerNoAufsCheck) GetCheckDefinition() CheckDefinition {
 	return dc
 }
 
 func (dc *DockerNoAufsCheck) AuditCheck() (bool, error) {
-	client, err := docker.NewClient(DockerUnixSocket)
+	client, err := getDockerAPIConnection()
 
 	if err != nil {
 		// TODO: log error message
@@ -34,12 +30,14 @@ func (dc *DockerNoAufsCheck) AuditCheck() (bool, error) {
 
 type DockerNoAufsCheck struct {
 	*CheckDefinitionImpl
+	DockerAPICheck
 }
 
 func makeDockerNoAufsCheck() Check {
 	return &DockerNoAufsCheck{
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:  "CIS-Docker-Benchmark-2.7",
+			severity:    SeverityLow,
 			category:    `Docker daemon configuration`,
 			name:        `Do not use the aufs storage driver`,
 			description: `Do not use 'aufs' as storage driver for your Docker instance.`,
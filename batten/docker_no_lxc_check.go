@@ -1,10 +1,6 @@
 package batten
 
-import (
-	"strings"
-
-	docker "github.com/fsouza/go-dockerclient"
-)
+import "strings"
 
 func (dc *DockerNoLxcCheck) GetCheckDefinition() CheckDefinition {
 	return dc
@@ -17,7 +13,7 @@ func (dc *DockerNoLxcCheck) GetCheckDefinition() CheckDefinition {
 // docker -d --exec-driver=lxc
 //
 func (dc *DockerNoLxcCheck) AuditCheck() (bool, error) {
-	client, err := docker.NewClient(DockerUnixSocket)
+	client, err := getDockerAPIConnection()
 
 	if err != nil {
 		// TODO: log error message
@@ -42,12 +38,14 @@ func (dc *DockerNoLxcCheck) AuditCheck() (bool, error) {
 
 type DockerNoLxcCheck struct {
 	*CheckDefinitionImpl
+	DockerAPICheck
 }
 
 func makeDockerNoLxcCheck() Check {
 	return &DockerNoLxcCheck{
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:   "CIS-Docker-Benchmark-2.1",
+			severity:     SeverityLow,
 			category:     "Docker Daemon Configuration",
 			name:         "Do not use lxc execution driver",
 			description:  "The default Docker execution driver is 'libcontainer'. LXC as an execution driver is optional and just has legacy support.",
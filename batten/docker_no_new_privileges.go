@@ -0,0 +1,46 @@
+package batten
+
+func (dc *DockerNoNewPrivilegesCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+func (dc *DockerNoNewPrivilegesCheck) AuditCheck() (bool, error) {
+	options, err := readDaemonOptions(dc.dockerPidFile, "no-new-privileges")
+	if err != nil {
+		return false, err
+	}
+
+	return options.NoNewPrivileges, nil
+}
+
+type DockerNoNewPrivilegesCheck struct {
+	*CheckDefinitionImpl
+	DaemonCheck
+	dockerPidFile string
+}
+
+func makeDockerNoNewPrivilegesCheck() Check {
+	return &DockerNoNewPrivilegesCheck{
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "Batten-Daemon-No-New-Privileges",
+			category:    `Docker daemon configuration`,
+			name:        `Restrict containers from acquiring new privileges by default`,
+			description: `Run the Docker daemon with '--no-new-privileges', so that processes in containers cannot gain privileges through setuid or setgid binaries or file capabilities unless a container opts out.`,
+			rationale:   `A process that can gain privileges through a setuid binary can turn a compromised unprivileged user of a container into root in it. Setting 'no-new-privileges' as the daemon's default protects every container, not only those started with '--security-opt=no-new-privileges'.`,
+			auditDescription: `$> ps -ef | grep dockerd
+$> cat /etc/docker/daemon.json
+Ensure that '--no-new-privileges' is given, or that "no-new-privileges" is true in daemon.json.`,
+			remediation: `Start the daemon with:
+
+$> dockerd --no-new-privileges
+
+or set "no-new-privileges": true in /etc/docker/daemon.json.`,
+			impact:       `Containers whose processes rely on setuid or setgid binaries, such as 'sudo' or 'ping' on some images, can no longer use them.`,
+			defaultValue: `By default, containers may acquire new privileges.`,
+			references: []string{
+				"https://docs.docker.com/engine/reference/commandline/dockerd/",
+				"https://www.kernel.org/doc/Documentation/prctl/no_new_privs.txt",
+			},
+		},
+	}
+}
@@ -17,6 +17,7 @@ func makeDockerNoUnnecessaryPackagesCheck() Check {
 	return &DockerNoUnnecessaryPackagesCheck{
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:  "CIS-Docker-Benchmark-4.3",
+			severity:    SeverityLow,
 			category:    `Container Images and Build File`,
 			name:        `Do not install unnecessary packages in the container`,
 			description: `Containers tend to be minimal and slim down versions of the Operating System. Do not  install anything that does not justify the purpose of container. `,
@@ -12,7 +12,7 @@ func (dc *DockerPartitionCheck) GetCheckDefinition() CheckDefinition {
 }
 
 func (dc *DockerPartitionCheck) AuditCheck() (bool, error) {
-	bytes, err := ioutil.ReadFile(dc.fstab)
+	bytes, err := ioutil.ReadFile(hostPath(dc.fstab))
 
 	if err != nil {
 		return false, err
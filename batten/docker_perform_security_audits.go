@@ -17,6 +17,7 @@ func makeDockerPerformSecurityAudits() Check {
 	return &DockerPerformSecurityAudits{
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:       "CIS-Docker-Benchmark-6.1",
+			severity:         SeverityLow,
 			category:         "Docker Security Operations",
 			name:             `Perform regular security audits of your host system and containers`,
 			description:      `Perform regular security audits of your host system and containers to identify any mis- configurations or vulnerabilities that could expose your system to compromise.`,
@@ -1,55 +1,27 @@
 package batten
 
-import (
-	"errors"
-	"strings"
-)
-
 func (dc *DockerPortCheck) GetCheckDefinition() CheckDefinition {
 	return dc
 }
 
-func (dc *DockerPortCheck) lookForPorts(args []string) (bool, error) {
-	var prev string
-	for _, arg := range args {
-		if len(arg) > 0 {
-			if prev == "-H" {
-				if !stringInSlice(arg, dc.whiteListed) {
-					// TODO log something?
-					return false, nil
-				}
-			} else if strings.HasPrefix(arg, "-H=") {
-				arg = arg[3:]
-				if !stringInSlice(arg, dc.whiteListed) {
-					// TODO log something?
-					return false, nil
-				}
-			}
-
-			prev = arg
-		}
-	}
-	return true, nil
-}
-
 func (dc *DockerPortCheck) AuditCheck() (bool, error) {
-
-	succ, args, err := readDockerDaemonArgs(dc.dockerPidFile)
-
-	// TODO: also try a lsof -i -p <pid of docker> -a check??
-
+	options, err := readDaemonOptions(dc.dockerPidFile, "host")
 	if err != nil {
 		return false, err
 	}
 
-	if succ {
-		return dc.lookForPorts(args)
+	for _, host := range options.Hosts {
+		if !stringInSlice(host, dc.whiteListed) {
+			// TODO log something?
+			return false, nil
+		}
 	}
-	return false, errors.New("Docker daemon not running")
+	return true, nil
 }
 
 type DockerPortCheck struct {
 	*CheckDefinitionImpl
+	DaemonCheck
 	// TODO: make configurable
 	dockerPidFile string
 	whiteListed   []string
@@ -59,6 +31,7 @@ func makeDockerPortCheck() Check {
 	return &DockerPortCheck{
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:  "CIS-Docker-Benchmark-2.8",
+			severity:    SeverityHigh,
 			category:    "Docker Daemon Configuration",
 			name:        `Do not bind Docker to another IP/Port or a Unix socket`,
 			description: `It is possible to make the Docker daemon to listen on a specific IP and port and any other Unix socket other than default Unix socket. Do not bind Docker daemon to another IP/Port or a Unix socket.`,
@@ -17,6 +17,7 @@ func makeDockerRemoveNonEssentialSvcsCheck() Check {
 	return &DockerRemoveNonEssentialSvcsCheck{
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:   "CIS-Docker-Benchmark-1.5",
+			severity:     SeverityLow,
 			category:     "Host Configuration",
 			name:         "Remove all non-essential services from the host",
 			impact:       "None",
@@ -1,58 +1,187 @@
 package batten
 
-import "github.com/fsouza/go-dockerclient"
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fsouza/go-dockerclient"
+)
+
+// defaultCapabilities are the capabilities Docker gives containers unless
+// told otherwise.
+var defaultCapabilities = []string{
+	"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL", "MKNOD",
+	"NET_BIND_SERVICE", "NET_RAW", "SETFCAP", "SETGID", "SETPCAP", "SETUID",
+	"SYS_CHROOT",
+}
+
+// allCapabilities are the capabilities of Linux, which 'ALL' stands for.
+var allCapabilities = []string{
+	"AUDIT_CONTROL", "AUDIT_READ", "AUDIT_WRITE", "BLOCK_SUSPEND", "BPF",
+	"CHECKPOINT_RESTORE", "CHOWN", "DAC_OVERRIDE", "DAC_READ_SEARCH", "FOWNER",
+	"FSETID", "IPC_LOCK", "IPC_OWNER", "KILL", "LEASE", "LINUX_IMMUTABLE",
+	"MAC_ADMIN", "MAC_OVERRIDE", "MKNOD", "NET_ADMIN", "NET_BIND_SERVICE",
+	"NET_BROADCAST", "NET_RAW", "PERFMON", "SETFCAP", "SETGID", "SETPCAP",
+	"SETUID", "SYSLOG", "SYS_ADMIN", "SYS_BOOT", "SYS_CHROOT", "SYS_MODULE",
+	"SYS_NICE", "SYS_PACCT", "SYS_PTRACE", "SYS_RAWIO", "SYS_RESOURCE",
+	"SYS_TIME", "SYS_TTY_CONFIG", "WAKE_ALARM",
+}
+
+// capabilityName returns the name of a capability as given to
+// '--cap-add' or '--cap-drop', which may be lower case or prefixed with
+// CAP_, as listed in allCapabilities.
+func capabilityName(c string) string {
+	return strings.TrimPrefix(strings.ToUpper(c), "CAP_")
+}
+
+// effectiveCapabilities returns the capabilities a container runs with,
+// the way the daemon works them out: a privileged container, or one given
+// 'ALL', has every capability but those it drops; one that drops 'ALL'
+// has only those it adds; others have the default ones but those they
+// drop, and those they add.
+func effectiveCapabilities(privileged bool, capAdd []string, capDrop []string) []string {
+	dropped := make(map[string]bool)
+	for _, c := range capDrop {
+		dropped[capabilityName(c)] = true
+	}
+
+	effective := make(map[string]bool)
+	switch {
+	case privileged:
+		for _, c := range allCapabilities {
+			effective[c] = true
+		}
+	case hasCapability(capAdd, "ALL"):
+		for _, c := range allCapabilities {
+			if !dropped[c] {
+				effective[c] = true
+			}
+		}
+	case dropped["ALL"]:
+		for _, c := range capAdd {
+			effective[capabilityName(c)] = true
+		}
+	default:
+		for _, c := range defaultCapabilities {
+			if !dropped[c] {
+				effective[c] = true
+			}
+		}
+		for _, c := range capAdd {
+			effective[capabilityName(c)] = true
+		}
+	}
+
+	var caps []string
+	for c := range effective {
+		caps = append(caps, c)
+	}
+	sort.Strings(caps)
+	return caps
+}
+
+// allowedCapabilities returns the capabilities the policy allows the
+// container named `name` running `image`.
+func (dc *DockerRestrictKernel) allowedCapabilities(image string, name string) map[string]bool {
+	allowed := make(map[string]bool)
+	for _, c := range dc.policy.Allowed {
+		allowed[capabilityName(c)] = true
+	}
+	for i := range dc.policy.Containers {
+		rule := &dc.policy.Containers[i]
+		if rule.matches(image, name) {
+			for _, c := range rule.Allowed {
+				allowed[capabilityName(c)] = true
+			}
+		}
+	}
+	if allowed["ALL"] {
+		for _, c := range allCapabilities {
+			allowed[c] = true
+		}
+	}
+	return allowed
+}
+
+func (dc *DockerRestrictKernel) usePolicy(policy *Policy) {
+	if policy.Capabilities != nil {
+		dc.policy = *policy.Capabilities
+	}
+}
 
 func (dc *DockerRestrictKernel) GetCheckDefinition() CheckDefinition {
 	return dc
 }
 
-func (dc *DockerRestrictKernel) AuditCheck() (bool, error) {
-	client, err := docker.NewClient(DockerUnixSocket)
+func (dc *DockerRestrictKernel) AuditFindings() (bool, []Finding, error) {
+	client, err := getDockerAPIConnection()
 
 	if err != nil {
 		// TODO: log error message
-		return false, err
+		return false, nil, err
 	}
 
-	containers, err := client.ListContainers(docker.ListContainersOptions{All: true})
+	containers, err := client.ListContainers(docker.ListContainersOptions{})
 
 	if err != nil {
 		// TODO: log error message
-		return false, err
+		return false, nil, err
 	}
 
+	var findings []Finding
 	for _, c := range containers {
-		if cc, err := client.InspectContainer(c.ID); err == nil {
-			if len(cc.HostConfig.CapAdd) <= 0 {
-				return true, nil
-			}
+		cc, err := client.InspectContainer(c.ID)
+		if err != nil {
+			return false, nil, err
+		}
+		if cc.HostConfig == nil {
+			continue
+		}
 
-			// TODO: do better check here.
-			for _, sysc := range cc.HostConfig.CapAdd {
-				if dc.blockedCalls[sysc] {
-					return false, nil
-				}
+		image := c.Image
+		if cc.Config != nil && cc.Config.Image != "" {
+			image = cc.Config.Image
+		}
+		allowed := dc.allowedCapabilities(image, cc.Name)
+		var excess []string
+		for _, c := range effectiveCapabilities(cc.HostConfig.Privileged, cc.HostConfig.CapAdd, cc.HostConfig.CapDrop) {
+			if !allowed[c] {
+				excess = append(excess, c)
 			}
 		}
+		if len(excess) > 0 {
+			findings = append(findings, Finding{
+				Subject: fmt.Sprintf("%s (%s)", strings.TrimPrefix(cc.Name, "/"), image),
+				Detail:  "has capabilities the policy does not allow: " + strings.Join(excess, ", "),
+			})
+		}
 	}
 
-	return true, nil
+	return len(findings) == 0, findings, nil
+}
+
+func (dc *DockerRestrictKernel) AuditCheck() (bool, error) {
+	succ, _, err := dc.AuditFindings()
+	return succ, err
 }
 
 type DockerRestrictKernel struct {
 	*CheckDefinitionImpl
-	blockedCalls map[string]bool
+	DockerAPICheck
+	policy CapabilityPolicy
 }
 
 func makeDockerRestrictKernel() Check {
 	return &DockerRestrictKernel{
-		blockedCalls: map[string]bool{
-			"NET_ADMIN":  true,
-			"SYS_ADMIN":  true,
-			"SYS_MODULE": true,
+		// unless told otherwise, containers may keep the default
+		// capabilities, but not be given more
+		policy: CapabilityPolicy{
+			Allowed: defaultCapabilities,
 		},
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:  "CIS-Docker-Benchmark-5.4",
+			severity:    SeverityHigh,
 			category:    `Container Runtime`,
 			name:        `Restrict Linux Kernel Capabilities within containers`,
 			description: `By default, Docker starts containers with a restricted set of Linux Kernel Capabilities. It means that any process may be granted the required capabilities instead of root access. Using Linux Kernel Capabilities, the processes do not have to run as root for almost all the specific areas where root privileges are usually needed.`,
@@ -64,7 +193,8 @@ SYS_ADMIN
 SYS_MODULE
 `,
 			auditDescription: `docker ps -q | xargs docker inspect --format '{{ .Id }}: CapAdd={{ .HostConfig.CapAdd }} CapDrop={{ .HostConfig.CapDrop }}' 
-Verify that the added and dropped Linux Kernel Capabilities are in line with the ones needed for container process for each container instance.`,
+Verify that the added and dropped Linux Kernel Capabilities are in line with the ones needed for container process for each container instance.
+The capabilities each container ends up with are compared to the 'capabilities' section of the policy given with 'batten check --policy', which allows the default capabilities unless it says otherwise.`,
 			remediation: `Execute the below command to add needed capabilities: 
 $> docker run --cap-add={"Capability 1","Capability 2"} <Run arguments> <Container Image Name or ID> <Command> 
 For example, 
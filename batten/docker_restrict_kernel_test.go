@@ -0,0 +1,86 @@
+package batten
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func TestEffectiveCapabilities(t *testing.T) {
+	tests := []struct {
+		privileged bool
+		capAdd     []string
+		capDrop    []string
+		expected   []string
+	}{
+		{false, nil, nil, defaultCapabilities},
+		{false, []string{"NET_ADMIN"}, []string{"ALL"}, []string{"NET_ADMIN"}},
+		{false, nil, []string{"all"}, nil},
+		{false, []string{"cap_sys_admin"}, []string{"CAP_NET_RAW", "MKNOD"}, []string{
+			"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL",
+			"NET_BIND_SERVICE", "SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_ADMIN",
+			"SYS_CHROOT",
+		}},
+		{true, nil, []string{"ALL"}, allCapabilities},
+	}
+	for _, test := range tests {
+		caps := effectiveCapabilities(test.privileged, test.capAdd, test.capDrop)
+		if !reflect.DeepEqual(caps, test.expected) {
+			t.Errorf("privileged=%v add=%v drop=%v: got %v, expected %v", test.privileged, test.capAdd, test.capDrop, caps, test.expected)
+		}
+	}
+
+	// 'ALL' gives every capability but those dropped
+	caps := effectiveCapabilities(false, []string{"ALL"}, []string{"SYS_MODULE"})
+	if len(caps) != len(allCapabilities)-1 || stringInSlice("SYS_MODULE", caps) {
+		t.Errorf("unexpected capabilities %v", caps)
+	}
+}
+
+func TestRestrictKernel(t *testing.T) {
+	server := newFakeDocker(t)
+	defer server.Stop()
+	server.run(t, "web", &docker.Config{Image: "nginx"}, &docker.HostConfig{CapDrop: []string{"ALL"}, CapAdd: []string{"NET_BIND_SERVICE"}})
+	server.run(t, "calico", &docker.Config{Image: "registry.example.com/cni/calico:v3"}, &docker.HostConfig{CapDrop: []string{"ALL"}, CapAdd: []string{"NET_ADMIN", "NET_RAW", "SYS_ADMIN"}})
+	server.run(t, "app", &docker.Config{Image: "app"}, &docker.HostConfig{})
+	// only running containers are audited
+	server.create(t, "stopped", &docker.Config{Image: "app"}, &docker.HostConfig{CapAdd: []string{"ALL"}})
+	UseDockerClient(server.client)
+	defer UseDockerClient(nil)
+
+	check := makeDockerRestrictKernel().(*DockerRestrictKernel)
+	check.usePolicy(&Policy{Capabilities: &CapabilityPolicy{
+		Allowed: []string{"NET_BIND_SERVICE"},
+		Containers: []CapabilityRule{
+			{ContainerPattern: ContainerPattern{Image: "registry.example.com/cni/*"}, Allowed: []string{"NET_ADMIN", "NET_RAW"}},
+		},
+	}})
+	succ, findings, err := check.AuditFindings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Finding{
+		{Subject: "calico (registry.example.com/cni/calico:v3)", Detail: "has capabilities the policy does not allow: SYS_ADMIN"},
+		{Subject: "app (app)", Detail: "has capabilities the policy does not allow: " + strings.Join([]string{
+			"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL", "MKNOD",
+			"NET_RAW", "SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT",
+		}, ", ")},
+	}
+	if succ || !reflect.DeepEqual(findings, expected) {
+		t.Errorf("got %v, expected %v", findings, expected)
+	}
+
+	// by default, only capabilities beyond the default ones are reported
+	_, findings, err = makeDockerRestrictKernel().(*DockerRestrictKernel).AuditFindings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []Finding{
+		{Subject: "calico (registry.example.com/cni/calico:v3)", Detail: "has capabilities the policy does not allow: NET_ADMIN, SYS_ADMIN"},
+	}
+	if !reflect.DeepEqual(findings, expected) {
+		t.Errorf("got %v, expected %v", findings, expected)
+	}
+}
@@ -1,34 +1,21 @@
 package batten
 
-import "strings"
-
 func (dc *DockerRestrictedNetworkTrafficCheck) GetCheckDefinition() CheckDefinition {
 	return dc
 }
 
-func (dc *DockerRestrictedNetworkTrafficCheck) lookForIccFlag(argv string) (bool, error) {
-	if strings.Contains(argv, "--icc=false") {
-		return true, nil
-	}
-	return false, nil
-}
-
 func (dc *DockerRestrictedNetworkTrafficCheck) AuditCheck() (bool, error) {
-	succ, args, err := readDockerDaemonArgs(dc.dockerPidFile)
-
+	options, err := readDaemonOptions(dc.dockerPidFile, "icc")
 	if err != nil {
 		return false, err
 	}
 
-	if succ {
-		argv := strings.Join(args, " ")
-		return dc.lookForIccFlag(argv)
-	}
-	return false, nil
+	return !options.ICC, nil
 }
 
 type DockerRestrictedNetworkTrafficCheck struct {
 	*CheckDefinitionImpl
+	DaemonCheck
 	dockerPidFile string
 }
 
@@ -52,6 +39,5 @@ Ensure that the '--icc' parameter is set to 'false'.`,
 
 $> docker -d --icc=false`,
 		},
-		dockerPidFile: "/var/run/docker.pid",
 	}
 }
@@ -0,0 +1,158 @@
+package batten
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// seccompBlockedSyscalls are system calls Docker's default seccomp profile
+// denies to containers that were not given extra capabilities.
+var seccompBlockedSyscalls = []string{
+	"acct", "add_key", "bpf", "clock_adjtime", "clock_settime", "create_module",
+	"delete_module", "finit_module", "get_kernel_syms", "get_mempolicy",
+	"init_module", "ioperm", "iopl", "kcmp", "kexec_file_load", "kexec_load",
+	"keyctl", "lookup_dcookie", "mbind", "mount", "move_pages",
+	"name_to_handle_at", "nfsservctl", "open_by_handle_at", "perf_event_open",
+	"pivot_root", "process_vm_readv", "process_vm_writev", "query_module",
+	"quotactl", "reboot", "request_key", "set_mempolicy", "setns", "settimeofday",
+	"stime", "swapoff", "swapon", "_sysctl", "sysfs", "umount", "umount2",
+	"unshare", "uselib", "userfaultfd", "ustat", "vm86", "vm86old",
+}
+
+// seccompProfile is the part of a seccomp profile that says what it allows.
+type seccompProfile struct {
+	DefaultAction string `json:"defaultAction"`
+	Syscalls      []struct {
+		Name     string            `json:"name"`
+		Names    []string          `json:"names"`
+		Action   string            `json:"action"`
+		Args     []json.RawMessage `json:"args"`
+		Includes struct {
+			Caps []string `json:"caps"`
+		} `json:"includes"`
+	} `json:"syscalls"`
+}
+
+// seccompProfileFindings checks that a custom seccomp profile denies what
+// the default profile denies: its default action must deny, and it must not
+// allow any of the system calls the default profile blocks, unless only
+// for some arguments or for containers given a capability.
+func seccompProfileFindings(filename string, data []byte) []Finding {
+	var profile seccompProfile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return []Finding{{Subject: filename, Detail: fmt.Sprintf("is not a valid seccomp profile: %v", err)}}
+	}
+
+	var findings []Finding
+	switch profile.DefaultAction {
+	case "SCMP_ACT_ERRNO", "SCMP_ACT_KILL", "SCMP_ACT_KILL_PROCESS", "SCMP_ACT_KILL_THREAD", "SCMP_ACT_TRAP":
+	default:
+		findings = append(findings, Finding{
+			Subject: filename,
+			Detail:  fmt.Sprintf("its default action is '%s', so that every system call it does not list is allowed", profile.DefaultAction),
+		})
+	}
+
+	blocked := make(map[string]bool)
+	for _, name := range seccompBlockedSyscalls {
+		blocked[name] = true
+	}
+	allowed := make(map[string]bool)
+	for _, rule := range profile.Syscalls {
+		if rule.Action != "SCMP_ACT_ALLOW" && rule.Action != "SCMP_ACT_LOG" {
+			continue
+		}
+		if len(rule.Args) > 0 || len(rule.Includes.Caps) > 0 {
+			continue
+		}
+		names := rule.Names
+		if rule.Name != "" {
+			names = append(names, rule.Name)
+		}
+		for _, name := range names {
+			if blocked[name] {
+				allowed[name] = true
+			}
+		}
+	}
+	if len(allowed) > 0 {
+		var names []string
+		for name := range allowed {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		findings = append(findings, Finding{
+			Subject: filename,
+			Detail:  "allows system calls the default profile blocks: " + strings.Join(names, ", "),
+		})
+	}
+	return findings
+}
+
+func (dc *DockerSeccompProfileCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+func (dc *DockerSeccompProfileCheck) AuditFindings() (bool, []Finding, error) {
+	options, err := readDaemonOptions(dc.dockerPidFile, "seccomp-profile")
+	if err != nil {
+		return false, nil, err
+	}
+
+	switch options.SeccompProfile {
+	case "", "builtin":
+		return true, nil, nil
+	case "unconfined":
+		return false, []Finding{{
+			Subject: "--seccomp-profile",
+			Detail:  "containers run without a seccomp profile by default",
+		}}, nil
+	}
+
+	data, err := readDaemonFile(dc.dockerPidFile, options.SeccompProfile)
+	if err != nil {
+		return false, nil, err
+	}
+	if data == nil {
+		return false, nil, fmt.Errorf("could not find the seccomp profile %s", options.SeccompProfile)
+	}
+	findings := seccompProfileFindings(options.SeccompProfile, data)
+	return len(findings) == 0, findings, nil
+}
+
+func (dc *DockerSeccompProfileCheck) AuditCheck() (bool, error) {
+	succ, _, err := dc.AuditFindings()
+	return succ, err
+}
+
+type DockerSeccompProfileCheck struct {
+	*CheckDefinitionImpl
+	DaemonCheck
+	dockerPidFile string
+}
+
+func makeDockerSeccompProfileCheck() Check {
+	return &DockerSeccompProfileCheck{
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "Batten-Daemon-Seccomp-Profile",
+			severity:    SeverityHigh,
+			category:    `Docker daemon configuration`,
+			name:        `Do not weaken the default seccomp profile`,
+			description: `Verify that the daemon's default seccomp profile, given with '--seccomp-profile' or "seccomp-profile" in daemon.json, is not 'unconfined', and that a custom profile denies by default and does not allow the system calls Docker's default profile blocks.`,
+			rationale:   `The default seccomp profile blocks about fifty system calls that containers do not need and that have been used to escape them, such as 'mount', 'unshare', 'keyctl' and 'open_by_handle_at'. A daemon-wide profile that allows them, or no profile at all, exposes every container that does not bring its own profile.`,
+			auditDescription: `$> docker info --format '{{ .SecurityOptions }}'
+$> ps -ef | grep dockerd
+$> cat /etc/docker/daemon.json
+Ensure that the seccomp profile is not 'unconfined', and review any custom profile against Docker's default one.`,
+			remediation:  `Remove '--seccomp-profile' to use the default profile, or base the custom profile on Docker's default one and allow only the system calls the containers need, for the capabilities or arguments they need them with.`,
+			impact:       `None, for containers that do not need the blocked system calls.`,
+			defaultValue: `By default, Docker's built-in seccomp profile is applied to every container.`,
+			references: []string{
+				"https://docs.docker.com/engine/security/seccomp/",
+				"https://github.com/moby/moby/blob/master/profiles/seccomp/default.json",
+			},
+		},
+	}
+}
@@ -0,0 +1,43 @@
+package batten
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSeccompProfileFindings(t *testing.T) {
+	tests := []struct {
+		profile  string
+		findings []string
+	}{
+		{`{
+			"defaultAction": "SCMP_ACT_ERRNO",
+			"syscalls": [
+				{"names": ["read", "write", "clone"], "action": "SCMP_ACT_ALLOW"},
+				{"names": ["mount", "umount2"], "action": "SCMP_ACT_ALLOW", "includes": {"caps": ["CAP_SYS_ADMIN"]}},
+				{"names": ["personality"], "action": "SCMP_ACT_ALLOW", "args": [{"index": 0, "value": 0, "op": "SCMP_CMP_EQ"}]}
+			]
+		}`, nil},
+		{`{
+			"defaultAction": "SCMP_ACT_ERRNO",
+			"syscalls": [
+				{"names": ["read", "unshare", "mount"], "action": "SCMP_ACT_ALLOW"},
+				{"name": "keyctl", "action": "SCMP_ACT_ALLOW"},
+				{"names": ["ptrace"], "action": "SCMP_ACT_ERRNO"}
+			]
+		}`, []string{"allows system calls the default profile blocks: keyctl, mount, unshare"}},
+		{`{"defaultAction": "SCMP_ACT_ALLOW", "syscalls": [{"names": ["reboot"], "action": "SCMP_ACT_ERRNO"}]}`, []string{
+			"its default action is 'SCMP_ACT_ALLOW', so that every system call it does not list is allowed",
+		}},
+	}
+
+	for _, test := range tests {
+		var details []string
+		for _, finding := range seccompProfileFindings("/etc/docker/seccomp.json", []byte(test.profile)) {
+			details = append(details, finding.Detail)
+		}
+		if !reflect.DeepEqual(details, test.findings) {
+			t.Errorf("%s:\ngot      %v\nexpected %v", test.profile, details, test.findings)
+		}
+	}
+}
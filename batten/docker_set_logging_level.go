@@ -1,45 +1,21 @@
 package batten
 
-import (
-	"errors"
-	"strings"
-)
-
 func (dc *DockerSetLoggingLevelCheck) GetCheckDefinition() CheckDefinition {
 	return dc
 }
 
-func (dc *DockerSetLoggingLevelCheck) lookForLoggingLevel(argv string) (bool, error) {
-	if strings.Contains(argv, "--log-level=info") {
-		return true, nil
-	}
-	if strings.Contains(argv, "--log-level info") {
-		return true, nil
-	}
-
-	if strings.Contains(argv, "--log-level=") {
-		return false, nil
-	}
-
-	return true, nil
-}
-
 func (dc *DockerSetLoggingLevelCheck) AuditCheck() (bool, error) {
-	succ, args, err := readDockerDaemonArgs(dc.dockerPidFile)
-
+	options, err := readDaemonOptions(dc.dockerPidFile, "log-level")
 	if err != nil {
 		return false, err
 	}
 
-	if succ {
-		argv := strings.Join(args, " ")
-		return dc.lookForLoggingLevel(argv)
-	}
-	return false, errors.New("Docker daemon not running")
+	return options.LogLevel == "info", nil
 }
 
 type DockerSetLoggingLevelCheck struct {
 	*CheckDefinitionImpl
+	DaemonCheck
 	dockerPidFile string
 }
 
@@ -47,6 +23,7 @@ func makeDockerSetLoggingLevelCheck() Check {
 	return &DockerSetLoggingLevelCheck{
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:  "CIS-Docker-Benchmark-2.3",
+			severity:    SeverityLow,
 			category:    `Docker daemon configuration`,
 			name:        `Set the logging level`,
 			description: `Set Docker daemon log level to 'info'.`,
@@ -62,6 +39,5 @@ $> docker -d --log-level="info"`,
 				"https://docs.docker.com/reference/commandline/cli/#daemon",
 			},
 		},
-		dockerPidFile: "/var/run/docker.pid",
 	}
 }
@@ -11,7 +11,7 @@ func (dc *DockerSingleMainProcess) GetCheckDefinition() CheckDefinition {
 }
 
 func (dc *DockerSingleMainProcess) AuditCheck() (bool, error) {
-	client, err := docker.NewClient(DockerUnixSocket)
+	client, err := getDockerAPIConnection()
 
 	if err != nil {
 		// TODO: log error message
@@ -53,6 +53,7 @@ func (dc *DockerSingleMainProcess) AuditCheck() (bool, error) {
 
 type DockerSingleMainProcess struct {
 	*CheckDefinitionImpl
+	DockerAPICheck
 	processManagers []string
 }
 
@@ -64,6 +65,7 @@ func makeDockerSingleMainProcess() Check {
 		},
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:  "CIS-Docker-Benchmark-5.3",
+			severity:    SeverityLow,
 			category:    `Container Runtime`,
 			name:        `Verify that containers are running only a single main process`,
 			description: `In almost all cases, you should only run a single main process (that main process could spawn children, which is ok) in a single container. Decoupling applications into multiple containers makes it much easier to scale horizontally and reuse containers. If that service depends on another service, make use of container linking.`,
@@ -7,10 +7,14 @@ func (dc *DockerSocketFilePermsCheck) GetCheckDefinition() CheckDefinition {
 }
 
 func (dc *DockerSocketFilePermsCheck) AuditCheck() (bool, error) {
+	// audit the socket of the daemon batten talks to, rather than the
+	// default one the check is defined with
+	socket := *dc.FilePermsCheck
+	socket.filepath = dockerSocket
 
-	if PathExists(dc.filepath) {
+	if PathExists(socket.filepath) {
 		// TODO log actual perms for debugging
-		return dc.HasAtLeastPerms(os.FileMode(dc.targetPerms))
+		return socket.HasAtLeastPerms(os.FileMode(socket.targetPerms))
 	}
 
 	return true, nil
@@ -25,6 +29,7 @@ func makeDockerSocketFilePermsCheck() Check {
 	return &DockerSocketFilePermsCheck{
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:  "CIS-Docker-Benchmark-3.26",
+			severity:    SeverityHigh,
 			category:    `Docker daemon configuration files`,
 			name:        `Verify that Docker socket file permissions are set to 660 or more restrictive`,
 			description: `Verify that the Docker socket file has permissions of '660' or more restrictive.`,
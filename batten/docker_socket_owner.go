@@ -7,10 +7,14 @@ func (dc *DockerSocketOwnerCheck) GetCheckDefinition() CheckDefinition {
 }
 
 func (dc *DockerSocketOwnerCheck) AuditCheck() (bool, error) {
-	if PathExists(dc.filepath) {
-		return dc.validateOwnerAndGroupOwner()
+	// audit the socket of the daemon batten talks to, rather than the
+	// default one the check is defined with
+	socket := *dc.FileOwnerCheck
+	socket.filepath = dockerSocket
+	if PathExists(socket.filepath) {
+		return socket.validateOwnerAndGroupOwner()
 	} else {
-		return false, errors.New("Could not find path: " + dc.filepath)
+		return false, errors.New("Could not find path: " + socket.filepath)
 	}
 	return true, nil
 }
@@ -24,6 +28,7 @@ func makeDockerSocketOwnerCheck() Check {
 	return &DockerSocketOwnerCheck{
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:  "CIS-Docker-Benchmark-3.25",
+			severity:    SeverityHigh,
 			category:    `Docker daemon configuration files`,
 			name:        `Verify that Docker socket file ownership is set to root:docker`,
 			description: `Verify that the Docker socket file is owned by 'root' and group-owned by 'docker'.`,
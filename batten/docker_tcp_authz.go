@@ -0,0 +1,59 @@
+package batten
+
+func (dc *DockerTCPAuthzCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+func (dc *DockerTCPAuthzCheck) AuditFindings() (bool, []Finding, error) {
+	options, err := readDaemonOptions(dc.dockerPidFile, "host")
+	if err != nil {
+		return false, nil, err
+	}
+	if len(options.AuthorizationPlugins) > 0 {
+		return true, nil, nil
+	}
+
+	var findings []Finding
+	for _, host := range options.TCPHosts() {
+		findings = append(findings, Finding{
+			Subject: host,
+			Detail:  "the Docker API is served over the network without an authorization plugin",
+		})
+	}
+	return len(findings) == 0, findings, nil
+}
+
+func (dc *DockerTCPAuthzCheck) AuditCheck() (bool, error) {
+	succ, _, err := dc.AuditFindings()
+	return succ, err
+}
+
+type DockerTCPAuthzCheck struct {
+	*CheckDefinitionImpl
+	DaemonCheck
+	dockerPidFile string
+}
+
+func makeDockerTCPAuthzCheck() Check {
+	return &DockerTCPAuthzCheck{
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "Batten-Daemon-TCP-Authz",
+			severity:    SeverityHigh,
+			category:    `Docker daemon configuration`,
+			name:        `Do not expose the Docker API over the network without authorization`,
+			description: `Verify that a daemon listening on a TCP address, with '-H tcp://...' or "hosts" in daemon.json, uses an authorization plugin.`,
+			rationale:   `TLS client certificates only say who may connect. Every client with a certificate the daemon trusts has full control of the daemon, and so of the host, unless an authorization plugin restricts what it may do.`,
+			auditDescription: `$> ps -ef | grep dockerd
+$> cat /etc/docker/daemon.json
+If the daemon listens on a 'tcp://' address, ensure that '--authorization-plugin' or "authorization-plugins" is set too.`,
+			remediation: `Listen on the unix socket only, or start the daemon with an authorization plugin:
+
+$> dockerd -H unix:///var/run/docker.sock -H tcp://0.0.0.0:2376 --tlsverify ... --authorization-plugin=<plugin>`,
+			defaultValue: `By default, the daemon listens on the unix socket only.`,
+			references: []string{
+				"https://docs.docker.com/engine/security/protect-access/",
+				"https://docs.docker.com/engine/extend/plugins_authorization/",
+			},
+		},
+	}
+}
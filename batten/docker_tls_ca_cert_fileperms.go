@@ -1,27 +1,21 @@
 package batten
 
-import "errors"
-
 func (dc *DockerTLSCACertFilePermsCheck) GetCheckDefinition() CheckDefinition {
 	return dc
 }
 
 func (dc *DockerTLSCACertFilePermsCheck) AuditCheck() (bool, error) {
-	succ, args, err := readDockerDaemonArgs(dc.dockerPidFile)
-
+	options, err := readDaemonOptions(dc.dockerPidFile, "tlscacert")
 	if err != nil {
 		return false, err
 	}
 
-	if succ {
-		return dc.validateFromArgs("--tlscacert", args)
-	}
-
-	return false, errors.New("Docker daemon not running")
+	return dc.validatePath(options.TLSCACert)
 }
 
 type DockerTLSCACertFilePermsCheck struct {
 	*CheckDefinitionImpl
+	DaemonCheck
 	*FilePermsCheck
 	dockerPidFile string
 }
@@ -6,9 +6,8 @@ func (dc *DockerTLSCACertOwnerCheck) GetCheckDefinition() CheckDefinition {
 	return dc
 }
 
-func (dc *DockerTLSCACertOwnerCheck) validate(args []string) (bool, error) {
-	lookFor := "--tlscacert"
-	filepath := getArgValue(lookFor, args)
+func (dc *DockerTLSCACertOwnerCheck) validate(options *DaemonOptions) (bool, error) {
+	filepath := options.TLSCACert
 
 	if filepath != "" {
 		if PathExists(filepath) {
@@ -23,21 +22,17 @@ func (dc *DockerTLSCACertOwnerCheck) validate(args []string) (bool, error) {
 }
 
 func (dc *DockerTLSCACertOwnerCheck) AuditCheck() (bool, error) {
-	succ, args, err := readDockerDaemonArgs(dc.dockerPidFile)
-
+	options, err := readDaemonOptions(dc.dockerPidFile, "tlscacert")
 	if err != nil {
 		return false, err
 	}
 
-	if succ {
-		return dc.validate(args)
-	}
-
-	return false, errors.New("Docker daemon not running")
+	return dc.validate(options)
 }
 
 type DockerTLSCACertOwnerCheck struct {
 	*CheckDefinitionImpl
+	DaemonCheck
 	*FileOwnerCheck
 	dockerPidFile string
 }
@@ -27,14 +27,22 @@ func TestLookForTLSConfigString(t *testing.T) {
 		"docker",
 		"--tlscacert=" + expected,
 	}
-	found, err := dc.validate(args)
+	config, err := newDaemonConfig(args, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	options, err := config.Options()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, err := dc.validate(options)
 	if !found {
 		t.Fatal("Expected owner to be current user: "+expected, err)
 	}
 	// check that bad uids fail
 	dc.uid = 0
 	dc.gid = 0
-	found, err = dc.validate(args)
+	found, err = dc.validate(options)
 	if found {
 		t.Fatal("Expected owner to not be root:"+expected, err)
 	}
@@ -1,27 +1,21 @@
 package batten
 
-import "errors"
-
 func (dc *DockerTLSCertFilePermsCheck) GetCheckDefinition() CheckDefinition {
 	return dc
 }
 
 func (dc *DockerTLSCertFilePermsCheck) AuditCheck() (bool, error) {
-	succ, args, err := readDockerDaemonArgs(dc.dockerPidFile)
-
+	options, err := readDaemonOptions(dc.dockerPidFile, "tlscert")
 	if err != nil {
 		return false, err
 	}
 
-	if succ {
-		return dc.validateFromArgs("--tlscert", args)
-	}
-
-	return false, errors.New("Docker daemon not running")
+	return dc.validatePath(options.TLSCert)
 }
 
 type DockerTLSCertFilePermsCheck struct {
 	*CheckDefinitionImpl
+	DaemonCheck
 	*FilePermsCheck
 	dockerPidFile string
 }
@@ -6,9 +6,8 @@ func (dc *DockerTLSCertOwnerCheck) GetCheckDefinition() CheckDefinition {
 	return dc
 }
 
-func (dc *DockerTLSCertOwnerCheck) validate(args []string) (bool, error) {
-	lookFor := "--tlscert"
-	filepath := getArgValue(lookFor, args)
+func (dc *DockerTLSCertOwnerCheck) validate(options *DaemonOptions) (bool, error) {
+	filepath := options.TLSCert
 
 	if filepath != "" {
 		if PathExists(filepath) {
@@ -23,21 +22,17 @@ func (dc *DockerTLSCertOwnerCheck) validate(args []string) (bool, error) {
 }
 
 func (dc *DockerTLSCertOwnerCheck) AuditCheck() (bool, error) {
-	succ, args, err := readDockerDaemonArgs(dc.dockerPidFile)
-
+	options, err := readDaemonOptions(dc.dockerPidFile, "tlscert")
 	if err != nil {
 		return false, err
 	}
 
-	if succ {
-		return dc.validate(args)
-	}
-
-	return false, errors.New("Docker daemon not running")
+	return dc.validate(options)
 }
 
 type DockerTLSCertOwnerCheck struct {
 	*CheckDefinitionImpl
+	DaemonCheck
 	*FileOwnerCheck
 	dockerPidFile string
 }
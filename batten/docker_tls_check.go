@@ -1,48 +1,29 @@
 package batten
 
-import "errors"
-
 func (dc *DockerTLSCheck) GetCheckDefinition() CheckDefinition {
 	return dc
 }
 
 // TODO: could do this more accurately with lsof -i
-func (dc *DockerTLSCheck) lookForListeningConfig(args []string) bool {
-	var prev string
-	for _, arg := range args {
-		if len(arg) > 0 {
-			if prev == "-H" || prev == "-H=" {
-				return true
-			}
-			prev = arg
-		}
-	}
-	return false
+func (dc *DockerTLSCheck) lookForListeningConfig(options *DaemonOptions) bool {
+	return len(options.TCPHosts()) > 0
 }
 
-func (dc *DockerTLSCheck) lookForTLSConfigs(args []string) bool {
-
-	if !stringInSlice("--tlsverify", args) {
+func (dc *DockerTLSCheck) lookForTLSConfigs(options *DaemonOptions) bool {
+	if !options.TLSVerify {
 		return false
 	}
 	// TODO: this is probably actually optional if the cert
 	// is signed by a known good ca
-	// if !(stringInSlice(args, "--tlscacert")) {
+	// if options.TLSCACert == "" {
 	// return false
 	// }
-	if !stringInSlice("--tlscert", args) {
-		return false
-	}
-	if !stringInSlice("--tlskey", args) {
-		return false
-	}
-	return true
-
+	return options.TLSCert != "" && options.TLSKey != ""
 }
 
 func (dc *DockerTLSCheck) AuditCheck() (bool, error) {
 
-	succ, args, err := readDockerDaemonArgs(dc.dockerPidFile)
+	options, err := readDaemonOptions(dc.dockerPidFile, "host", "tlsverify", "tlscert", "tlskey")
 
 	// TODO: also try a lsof -i -p <pid of docker> -a check??
 
@@ -50,23 +31,16 @@ func (dc *DockerTLSCheck) AuditCheck() (bool, error) {
 		return false, err
 	}
 
-	if succ {
-
-		hasListener := dc.lookForListeningConfig(args)
-
-		if hasListener {
-			return dc.lookForTLSConfigs(args), nil
-		} else {
-			// it's ok
-			return true, nil
-		}
+	if dc.lookForListeningConfig(options) {
+		return dc.lookForTLSConfigs(options), nil
 	}
-
-	return false, errors.New("Docker daemon not running")
+	// it's ok
+	return true, nil
 }
 
 type DockerTLSCheck struct {
 	*CheckDefinitionImpl
+	DaemonCheck
 	dockerPidFile string
 }
 
@@ -74,6 +48,7 @@ func makeDockerTLSCheck() Check {
 	return &DockerTLSCheck{
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:  "CIS-Docker-Benchmark-2.9",
+			severity:    SeverityHigh,
 			category:    `Docker daemon configuration`,
 			name:        `Configure TLS authentication for Docker daemon`,
 			description: `It is possible to make the Docker daemon to listen on a specific IP and port and any other Unix socket other than default Unix socket. Configure TLS authentication to restrict access to Docker daemon via IP and Port.`,
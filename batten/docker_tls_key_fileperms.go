@@ -1,27 +1,21 @@
 package batten
 
-import "errors"
-
 func (dc *DockerTLSKeyFilePermsCheck) GetCheckDefinition() CheckDefinition {
 	return dc
 }
 
 func (dc *DockerTLSKeyFilePermsCheck) AuditCheck() (bool, error) {
-	succ, args, err := readDockerDaemonArgs(dc.dockerPidFile)
-
+	options, err := readDaemonOptions(dc.dockerPidFile, "tlskey")
 	if err != nil {
 		return false, err
 	}
 
-	if succ {
-		return dc.validateFromArgs("--tlskey", args)
-	}
-
-	return false, errors.New("Docker daemon not running")
+	return dc.validatePath(options.TLSKey)
 }
 
 type DockerTLSKeyFilePermsCheck struct {
 	*CheckDefinitionImpl
+	DaemonCheck
 	*FilePermsCheck
 	dockerPidFile string
 }
@@ -30,6 +24,7 @@ func makeDockerTLSKeyFilePermsCheck() Check {
 	return &DockerTLSKeyFilePermsCheck{
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:  "CIS-Docker-Benchmark-3.24",
+			severity:    SeverityHigh,
 			category:    `Docker daemon configuration files`,
 			name:        `Verify that Docker server certificate key file permissions are set to 400`,
 			description: `Verify that the Docker server certificate key file (the file that is passed alongwith '--tlskey' parameter) has permissions of '400'.`,
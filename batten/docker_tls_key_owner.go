@@ -6,9 +6,8 @@ func (dc *DockerTLSKeyOwnerCheck) GetCheckDefinition() CheckDefinition {
 	return dc
 }
 
-func (dc *DockerTLSKeyOwnerCheck) validate(args []string) (bool, error) {
-	lookFor := "--tlskey"
-	filepath := getArgValue(lookFor, args)
+func (dc *DockerTLSKeyOwnerCheck) validate(options *DaemonOptions) (bool, error) {
+	filepath := options.TLSKey
 
 	if filepath != "" {
 		if PathExists(filepath) {
@@ -23,22 +22,17 @@ func (dc *DockerTLSKeyOwnerCheck) validate(args []string) (bool, error) {
 }
 
 func (dc *DockerTLSKeyOwnerCheck) AuditCheck() (bool, error) {
-
-	succ, args, err := readDockerDaemonArgs(dc.dockerPidFile)
-
+	options, err := readDaemonOptions(dc.dockerPidFile, "tlskey")
 	if err != nil {
 		return false, err
 	}
 
-	if succ {
-		return dc.validate(args)
-	}
-
-	return false, errors.New("Docker daemon not running")
+	return dc.validate(options)
 }
 
 type DockerTLSKeyOwnerCheck struct {
 	*CheckDefinitionImpl
+	DaemonCheck
 	*FileOwnerCheck
 	dockerPidFile string
 }
@@ -47,6 +41,7 @@ func makeDockerTLSKeyOwnerCheck() Check {
 	return &DockerTLSKeyOwnerCheck{
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:  "CIS-Docker-Benchmark-3.23",
+			severity:    SeverityHigh,
 			category:    `Docker daemon configuration files`,
 			name:        `Verify that Docker server certificate key file ownership is set to root:root`,
 			description: `Verify that the Docker server certificate key file (the file that is passed alongwith '--tlskey' parameter) is owned and group-owned by 'root'.`,
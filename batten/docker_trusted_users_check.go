@@ -11,7 +11,7 @@ func (dc *DockerTrustedUsersCheck) GetCheckDefinition() CheckDefinition {
 }
 
 func (dc *DockerTrustedUsersCheck) AuditCheck() (bool, error) {
-	bytes, err := ioutil.ReadFile(dc.groupsFile)
+	bytes, err := ioutil.ReadFile(hostPath(dc.groupsFile))
 
 	if err != nil {
 		return false, err
@@ -54,6 +54,7 @@ func makeDockerTrustedUsersCheck() Check {
 	return &DockerTrustedUsersCheck{
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:   "CIS-Docker-Benchmark-1.7",
+			severity:     SeverityHigh,
 			category:     "Host Configuration",
 			name:         "Only allow trusted users to control Docker daemon",
 			impact:       "Rights to build and execute containers as normal user would be restricted.",
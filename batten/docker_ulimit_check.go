@@ -36,6 +36,17 @@ func (dc *DockerUlimitCheck) checkForFileUlimits(l *limits.Limits) bool {
 
 func (dc *DockerUlimitCheck) AuditCheck() (bool, error) {
 
+	// default ulimits set for containers, on the command line or in
+	// daemon.json, are what the benchmark asks for
+	options, err := readDaemonOptions(dc.dockerPidFile, "default-ulimit")
+	if err != nil {
+		return false, err
+	}
+	if len(options.DefaultUlimits) > 0 {
+		return true, nil
+	}
+
+	// otherwise, containers inherit the daemon's own limits
 	process, err := getDockerProcess(dc.dockerPidFile)
 
 	if err != nil {
@@ -60,6 +71,7 @@ func (dc *DockerUlimitCheck) AuditCheck() (bool, error) {
 
 type DockerUlimitCheck struct {
 	*CheckDefinitionImpl
+	DaemonCheck
 	// TODO: make configurable
 	dockerPidFile          string
 	processesUlimitMinimum int
@@ -1,8 +1,7 @@
 package batten
 
 import (
-	"fmt"
-
+	"github.com/Sirupsen/logrus"
 	docker "github.com/fsouza/go-dockerclient"
 )
 
@@ -25,13 +24,12 @@ func (dc *DockerUseTrustedImagesCheck) AuditCheck() (bool, error) {
 	// TODO: there needs to be a better way to check
 	// this against a policy.
 	for _, img := range images {
-
-		if !stringInSlice(img.RepoTags[0], dc.trustedRepoTags) {
-			imgDetails, err := client.InspectImage(img.ID)
-			fmt.Println("XXX", imgDetails.Author, err)
-			// TODO: log the failed repository
-			// return false, nil
+		if len(img.RepoTags) > 0 && stringInSlice(img.RepoTags[0], dc.trustedRepoTags) {
+			continue
 		}
+		// TODO: report the untrusted image as a finding
+		// return false, nil
+		logrus.Debugf("Image %s %v is not a trusted image", img.ID, img.RepoTags)
 	}
 
 	return true, nil
@@ -39,6 +37,7 @@ func (dc *DockerUseTrustedImagesCheck) AuditCheck() (bool, error) {
 
 type DockerUseTrustedImagesCheck struct {
 	*CheckDefinitionImpl
+	DockerAPICheck
 	trustedRepoTags []string
 }
 
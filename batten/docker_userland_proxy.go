@@ -0,0 +1,46 @@
+package batten
+
+func (dc *DockerUserlandProxyCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+func (dc *DockerUserlandProxyCheck) AuditCheck() (bool, error) {
+	options, err := readDaemonOptions(dc.dockerPidFile, "userland-proxy")
+	if err != nil {
+		return false, err
+	}
+
+	return !options.UserlandProxy, nil
+}
+
+type DockerUserlandProxyCheck struct {
+	*CheckDefinitionImpl
+	DaemonCheck
+	dockerPidFile string
+}
+
+func makeDockerUserlandProxyCheck() Check {
+	return &DockerUserlandProxyCheck{
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "Batten-Daemon-Userland-Proxy",
+			level:       2,
+			category:    `Docker daemon configuration`,
+			name:        `Disable the userland proxy`,
+			description: `Run the Docker daemon with '--userland-proxy=false', so that published ports are forwarded by iptables rules alone rather than by a 'docker-proxy' process for every port.`,
+			rationale:   `The userland proxy is a process, running as root, for every published port of every container. Where hairpin NAT is available it is not needed, and disabling it removes that attack surface and the resources it uses.`,
+			auditDescription: `$> ps -ef | grep dockerd
+$> cat /etc/docker/daemon.json
+Ensure that '--userland-proxy' is set to false, on the command line or in daemon.json.`,
+			remediation: `Start the daemon without the userland proxy:
+
+$> dockerd --userland-proxy=false
+
+or set "userland-proxy": false in /etc/docker/daemon.json.`,
+			impact:       `Older kernels that cannot do hairpin NAT need the userland proxy for containers to reach each other through published ports, and some container networking setups depend on it.`,
+			defaultValue: `By default, the userland proxy is enabled.`,
+			references: []string{
+				"https://docs.docker.com/config/containers/container-networking/",
+			},
+		},
+	}
+}
@@ -0,0 +1,46 @@
+package batten
+
+func (dc *DockerUsernsRemapCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+func (dc *DockerUsernsRemapCheck) AuditCheck() (bool, error) {
+	options, err := readDaemonOptions(dc.dockerPidFile, "userns-remap")
+	if err != nil {
+		return false, err
+	}
+
+	return options.UsernsRemap != "", nil
+}
+
+type DockerUsernsRemapCheck struct {
+	*CheckDefinitionImpl
+	DaemonCheck
+	dockerPidFile string
+}
+
+func makeDockerUsernsRemapCheck() Check {
+	return &DockerUsernsRemapCheck{
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "Batten-Daemon-Userns-Remap",
+			level:       2,
+			category:    `Docker daemon configuration`,
+			name:        `Enable user namespace support`,
+			description: `Run the Docker daemon with '--userns-remap', so that the users of containers, and root in particular, are mapped to unprivileged users of the host.`,
+			rationale:   `Without user namespace remapping, root in a container is root on the host. A process that breaks out of its container, or a container given access to host files through a volume, then acts with full privileges on the host. With remapping, container root is an unprivileged user of the host that owns nothing outside of Docker's own directories.`,
+			auditDescription: `$> ps -ef | grep dockerd
+$> cat /etc/docker/daemon.json
+Ensure that the '--userns-remap' option, or "userns-remap" in daemon.json, is set.`,
+			remediation: `Create the subordinate id ranges in '/etc/subuid' and '/etc/subgid', or let Docker create the 'dockremap' user, and start the daemon with user namespace remapping:
+
+$> dockerd --userns-remap=default
+
+or set "userns-remap": "default" in /etc/docker/daemon.json.`,
+			impact:       `Remapping is incompatible with some options, such as '--pid=host', '--net=host' and '--privileged', unless the container is also started with '--userns=host'. Images and containers are kept apart for every remapping, so existing images have to be pulled again.`,
+			defaultValue: `By default, user namespaces are not remapped.`,
+			references: []string{
+				"https://docs.docker.com/engine/security/userns-remap/",
+			},
+		},
+	}
+}
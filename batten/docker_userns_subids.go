@@ -0,0 +1,230 @@
+package batten
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultRemapUser is the user `--userns-remap=default` maps containers to.
+const DefaultRemapUser = "dockremap"
+
+// parseUsernsRemap returns the user and group named by `--userns-remap`,
+// which is `default`, `user`, `user:group`, `uid` or `uid:gid`. Without a
+// group, the group is named after the user.
+func parseUsernsRemap(value string) (string, string) {
+	if value == "default" {
+		return DefaultRemapUser, DefaultRemapUser
+	}
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return parts[0], parts[0]
+}
+
+// readHostIDs reads the names and ids of the accounts of /etc/passwd or
+// /etc/group.
+func readHostIDs(filename string) (map[string]int64, error) {
+	data, err := readHostFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]int64)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 3 {
+			continue
+		}
+		if id, err := strconv.ParseInt(fields[2], 10, 64); err == nil {
+			ids[fields[0]] = id
+		}
+	}
+	return ids, nil
+}
+
+// subIDRange is an entry of /etc/subuid or /etc/subgid: `count` ids from
+// `start` that `owner` may map its namespaces to.
+type subIDRange struct {
+	owner string
+	start int64
+	count int64
+	line  int
+}
+
+func (r subIDRange) end() int64 {
+	return r.start + r.count - 1
+}
+
+func (r subIDRange) String() string {
+	return fmt.Sprintf("%d-%d", r.start, r.end())
+}
+
+func (r subIDRange) overlaps(other subIDRange) bool {
+	return r.start <= other.end() && other.start <= r.end()
+}
+
+// subIDFindings checks the ranges of /etc/subuid or /etc/subgid, read as
+// `data`, that are owned by `owner`: there must be some, and they must not
+// map to id 0, to the ids of the host's own accounts in `ids`, or to the
+// ranges of anyone else.
+func subIDFindings(filename string, data []byte, owner string, ids map[string]int64) []Finding {
+	var findings []Finding
+	var ranges []subIDRange
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, ":")
+		var start, count int64
+		var err error
+		if len(fields) == 3 {
+			if start, err = strconv.ParseInt(fields[1], 10, 64); err == nil {
+				count, err = strconv.ParseInt(fields[2], 10, 64)
+			}
+		}
+		if len(fields) != 3 || fields[0] == "" || err != nil || start < 0 || count <= 0 {
+			findings = append(findings, Finding{
+				Subject: fmt.Sprintf("%s:%d", filename, line),
+				Detail:  fmt.Sprintf("invalid entry '%s'", text),
+			})
+			continue
+		}
+		ranges = append(ranges, subIDRange{owner: fields[0], start: start, count: count, line: line})
+	}
+
+	// the owner's entries may name it or give its id
+	names := map[string]bool{owner: true}
+	if id, ok := ids[owner]; ok {
+		names[strconv.FormatInt(id, 10)] = true
+	}
+	for name, id := range ids {
+		if strconv.FormatInt(id, 10) == owner {
+			names[name] = true
+		}
+	}
+
+	var accounts []string
+	for name := range ids {
+		accounts = append(accounts, name)
+	}
+	sort.Strings(accounts)
+
+	found := false
+	for i, r := range ranges {
+		if !names[r.owner] {
+			continue
+		}
+		found = true
+		subject := fmt.Sprintf("%s:%d", filename, r.line)
+
+		if r.start == 0 {
+			findings = append(findings, Finding{
+				Subject: subject,
+				Detail:  fmt.Sprintf("range %s of '%s' maps root in containers to root on the host", r, owner),
+			})
+		}
+		for j, other := range ranges {
+			if i != j && r.overlaps(other) {
+				findings = append(findings, Finding{
+					Subject: subject,
+					Detail:  fmt.Sprintf("range %s of '%s' overlaps range %s of '%s'", r, owner, other, other.owner),
+				})
+			}
+		}
+		for _, name := range accounts {
+			if id := ids[name]; id != 0 && id >= r.start && id <= r.end() {
+				findings = append(findings, Finding{
+					Subject: subject,
+					Detail:  fmt.Sprintf("range %s of '%s' includes the id of '%s' (%d)", r, owner, name, id),
+				})
+			}
+		}
+	}
+	if !found {
+		findings = append(findings, Finding{
+			Subject: filename,
+			Detail:  fmt.Sprintf("no range for '%s'", owner),
+		})
+	}
+	return findings
+}
+
+func (dc *DockerUsernsSubIDsCheck) GetCheckDefinition() CheckDefinition {
+	return dc
+}
+
+func (dc *DockerUsernsSubIDsCheck) AuditFindings() (bool, []Finding, error) {
+	options, err := readDaemonOptions(dc.dockerPidFile, "userns-remap")
+	if err != nil {
+		return false, nil, err
+	}
+	if options.UsernsRemap == "" {
+		// no ranges are used without remapping
+		return true, nil, nil
+	}
+	user, group := parseUsernsRemap(options.UsernsRemap)
+
+	var findings []Finding
+	for _, f := range []struct {
+		filename string
+		accounts string
+		owner    string
+	}{
+		{"/etc/subuid", "/etc/passwd", user},
+		{"/etc/subgid", "/etc/group", group},
+	} {
+		ids, err := readHostIDs(f.accounts)
+		if err != nil {
+			return false, nil, err
+		}
+		data, err := readHostFile(f.filename)
+		if err != nil {
+			return false, nil, err
+		}
+		findings = append(findings, subIDFindings(f.filename, data, f.owner, ids)...)
+	}
+	return len(findings) == 0, findings, nil
+}
+
+func (dc *DockerUsernsSubIDsCheck) AuditCheck() (bool, error) {
+	succ, _, err := dc.AuditFindings()
+	return succ, err
+}
+
+type DockerUsernsSubIDsCheck struct {
+	*CheckDefinitionImpl
+	DaemonCheck
+	dockerPidFile string
+}
+
+func makeDockerUsernsSubIDsCheck() Check {
+	return &DockerUsernsSubIDsCheck{
+		CheckDefinitionImpl: &CheckDefinitionImpl{
+			identifier:  "Batten-Userns-Subordinate-IDs",
+			level:       2,
+			category:    `Docker daemon configuration`,
+			name:        `Verify the subordinate ids of the user namespace remapping`,
+			description: `Verify that '/etc/subuid' and '/etc/subgid' give the remapping user and group valid ranges of ids, that do not start at 0 and overlap neither the ranges of other users nor the ids of the host's own accounts.`,
+			rationale:   `Containers' users are mapped to the remapping user's subordinate ids. A range that starts at 0 maps container root to host root, one that overlaps another user's range lets containers share ids with that user's namespaces, and one that includes the id of a host account makes a container user the owner of that account's files.`,
+			auditDescription: `$> grep dockremap /etc/subuid /etc/subgid
+$> cat /etc/subuid /etc/subgid
+Ensure that the ranges of the remapping user and group exist, and that no other range or account id falls within them.`,
+			remediation: `Give the remapping user and group a range of their own, above the ids of the host's accounts, for example:
+
+dockremap:165536:65536`,
+			defaultValue: `With '--userns-remap=default', Docker creates the 'dockremap' user and group and adds ranges for them after the highest existing range.`,
+			references: []string{
+				"https://docs.docker.com/engine/security/userns-remap/",
+				"http://man7.org/linux/man-pages/man5/subuid.5.html",
+			},
+		},
+	}
+}
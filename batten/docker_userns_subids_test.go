@@ -0,0 +1,58 @@
+package batten
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseUsernsRemap(t *testing.T) {
+	tests := []struct {
+		value, user, group string
+	}{
+		{"default", "dockremap", "dockremap"},
+		{"builder", "builder", "builder"},
+		{"builder:docker", "builder", "docker"},
+		{"1001:1001", "1001", "1001"},
+	}
+	for _, test := range tests {
+		if user, group := parseUsernsRemap(test.value); user != test.user || group != test.group {
+			t.Errorf("%s: got %s:%s, expected %s:%s", test.value, user, group, test.user, test.group)
+		}
+	}
+}
+
+func TestSubIDFindings(t *testing.T) {
+	ids := map[string]int64{"root": 0, "alice": 1000, "dockremap": 998, "bob": 100500}
+
+	tests := []struct {
+		data     string
+		owner    string
+		findings []Finding
+	}{
+		{"alice:100000:65536\ndockremap:165536:65536\n", "dockremap", nil},
+		// entries may give the owner's id instead of its name
+		{"998:165536:65536\n", "dockremap", nil},
+		{"alice:100000:65536\n", "dockremap", []Finding{
+			{Subject: "/etc/subuid", Detail: "no range for 'dockremap'"},
+		}},
+		{"dockremap:0:65536\n", "dockremap", []Finding{
+			{Subject: "/etc/subuid:1", Detail: "range 0-65535 of 'dockremap' maps root in containers to root on the host"},
+			{Subject: "/etc/subuid:1", Detail: "range 0-65535 of 'dockremap' includes the id of 'alice' (1000)"},
+			{Subject: "/etc/subuid:1", Detail: "range 0-65535 of 'dockremap' includes the id of 'dockremap' (998)"},
+		}},
+		{"# ranges\nalice:100000:65536\ndockremap:165000:65536\n", "dockremap", []Finding{
+			{Subject: "/etc/subuid:3", Detail: "range 165000-230535 of 'dockremap' overlaps range 100000-165535 of 'alice'"},
+		}},
+		{"dockremap:100000:65536\nbroken:1\n", "998", []Finding{
+			{Subject: "/etc/subuid:2", Detail: "invalid entry 'broken:1'"},
+			{Subject: "/etc/subuid:1", Detail: "range 100000-165535 of '998' includes the id of 'bob' (100500)"},
+		}},
+	}
+
+	for _, test := range tests {
+		findings := subIDFindings("/etc/subuid", []byte(test.data), test.owner, ids)
+		if !reflect.DeepEqual(findings, test.findings) {
+			t.Errorf("%q:\ngot      %v\nexpected %v", test.data, findings, test.findings)
+		}
+	}
+}
@@ -7,7 +7,7 @@ func (dc *DockerVerifyAppArmorProfile) GetCheckDefinition() CheckDefinition {
 }
 
 func (dc *DockerVerifyAppArmorProfile) AuditCheck() (bool, error) {
-	client, err := docker.NewClient(DockerUnixSocket)
+	client, err := getDockerAPIConnection()
 
 	if err != nil {
 		// TODO: log error message
@@ -40,6 +40,7 @@ func (dc *DockerVerifyAppArmorProfile) AuditCheck() (bool, error) {
 
 type DockerVerifyAppArmorProfile struct {
 	*CheckDefinitionImpl
+	DockerAPICheck
 }
 
 func makeDockerVerifyAppArmorProfile() Check {
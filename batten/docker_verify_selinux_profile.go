@@ -7,7 +7,7 @@ func (dc *DockerVerifySELinuxProfile) GetCheckDefinition() CheckDefinition {
 }
 
 func (dc *DockerVerifySELinuxProfile) AuditCheck() (bool, error) {
-	client, err := docker.NewClient(DockerUnixSocket)
+	client, err := getDockerAPIConnection()
 
 	if err != nil {
 		// TODO: log error message
@@ -40,12 +40,14 @@ func (dc *DockerVerifySELinuxProfile) AuditCheck() (bool, error) {
 
 type DockerVerifySELinuxProfile struct {
 	*CheckDefinitionImpl
+	DockerAPICheck
 }
 
 func makeDockerVerifySELinuxProfile() Check {
 	return &DockerVerifySELinuxProfile{
 		CheckDefinitionImpl: &CheckDefinitionImpl{
 			identifier:  "CIS-Docker-Benchmark-5.2",
+			level:       2,
 			category:    "Container Runtime",
 			name:        `Verify SELinux security options, if applicable`,
 			description: `SELinux is an effective and easy-to-use Linux application security system. It is available on quite a few Linux distributions by default such as Red Hat and Fedora.`,
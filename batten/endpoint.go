@@ -0,0 +1,298 @@
+package batten
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+const (
+	DefaultDockerSocket = "/var/run/docker.sock"
+	DefaultContext      = "default"
+)
+
+// Endpoint is a Docker daemon and the TLS material to connect to it with,
+// resolved the way the docker CLI resolves them.
+type Endpoint struct {
+	Host      string
+	TLSCACert string
+	TLSCert   string
+	TLSKey    string
+
+	// whether the daemon's certificate is not verified; without a CA
+	// certificate it is verified with the system's
+	TLSSkipVerify bool
+
+	// the daemon's pid file, if it is not the default one
+	PidFile string
+
+	// what the endpoint was resolved from, e.g. "DOCKER_HOST"
+	Source string
+
+	// how long a request to the daemon may take, if it is limited
+	Timeout time.Duration
+}
+
+// dockerSocket is the unix socket of the audited daemon, whose owner and
+// permissions are checked, and daemonPidFile is where its pid is found.
+var (
+	dockerSocket  = DefaultDockerSocket
+	daemonPidFile = DockerPidFile
+)
+
+// dockerConfigDir returns where the docker CLI keeps its configuration.
+func dockerConfigDir() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.Getenv("HOME"), ".docker")
+}
+
+// ResolveEndpoint works out which daemon to talk to. In order, it is
+// `host`, the Docker context named `context`, $DOCKER_HOST, the context
+// named by $DOCKER_CONTEXT or the CLI configuration's `currentContext`,
+// and finally the local socket, or the rootless one if only that exists.
+// Without a context, TLS material is taken from the environment.
+func ResolveEndpoint(host string, context string) (*Endpoint, error) {
+	var endpoint *Endpoint
+
+	switch {
+	case host != "":
+		endpoint = &Endpoint{Host: host, Source: "--host"}
+	case context != "":
+		return contextEndpoint(context, "--context")
+	case os.Getenv("DOCKER_HOST") != "":
+		endpoint = &Endpoint{Host: os.Getenv("DOCKER_HOST"), Source: "DOCKER_HOST"}
+	case os.Getenv("DOCKER_CONTEXT") != "":
+		return contextEndpoint(os.Getenv("DOCKER_CONTEXT"), "DOCKER_CONTEXT")
+	default:
+		current, err := currentContext()
+		if err != nil {
+			return nil, err
+		}
+		if current != "" && current != DefaultContext {
+			return contextEndpoint(current, "currentContext")
+		}
+		endpoint = localEndpoint()
+	}
+	endpoint.useTLSEnv()
+	return endpoint, nil
+}
+
+// useTLSEnv takes the endpoint's TLS material from $DOCKER_CERT_PATH, or
+// the docker CLI configuration, if $DOCKER_TLS_VERIFY or $DOCKER_TLS ask
+// for TLS. The server's certificate is verified with the CA found there
+// only with $DOCKER_TLS_VERIFY. This is how go-dockerclient's
+// NewClientFromEnv reads them, which the vendored version predates.
+func (e *Endpoint) useTLSEnv() {
+	verify := os.Getenv("DOCKER_TLS_VERIFY") != ""
+	if !verify && os.Getenv("DOCKER_TLS") == "" {
+		return
+	}
+	certPath := os.Getenv("DOCKER_CERT_PATH")
+	if certPath == "" {
+		certPath = dockerConfigDir()
+	}
+	e.TLSCert = filepath.Join(certPath, "cert.pem")
+	e.TLSKey = filepath.Join(certPath, "key.pem")
+	if verify {
+		e.TLSCACert = filepath.Join(certPath, "ca.pem")
+	} else {
+		e.TLSSkipVerify = true
+	}
+}
+
+// localEndpoint is the daemon listening on the default socket, or the
+// current user's rootless daemon if there is no default socket.
+func localEndpoint() *Endpoint {
+	if _, err := os.Stat(DefaultDockerSocket); err != nil {
+		runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+		if runtimeDir == "" {
+			runtimeDir = fmt.Sprintf("/run/user/%d", os.Getuid())
+		}
+		rootless := filepath.Join(runtimeDir, "docker.sock")
+		if _, err := os.Stat(rootless); err == nil {
+			return &Endpoint{
+				Host:    "unix://" + rootless,
+				PidFile: filepath.Join(runtimeDir, "docker.pid"),
+				Source:  "rootless",
+			}
+		}
+	}
+	return &Endpoint{Host: "unix://" + DefaultDockerSocket, Source: "default"}
+}
+
+func currentContext() (string, error) {
+	f, err := os.Open(filepath.Join(dockerConfigDir(), "config.json"))
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var config struct {
+		CurrentContext string `json:"currentContext"`
+	}
+	if err := json.NewDecoder(f).Decode(&config); err != nil {
+		return "", fmt.Errorf("could not read %s: %v", f.Name(), err)
+	}
+	return config.CurrentContext, nil
+}
+
+// contextEndpoint reads the docker endpoint of a context from the CLI's
+// context store, which keeps every context under the SHA-256 of its name.
+func contextEndpoint(name string, source string) (*Endpoint, error) {
+	if name == DefaultContext {
+		return localEndpoint(), nil
+	}
+
+	sum := sha256.Sum256([]byte(name))
+	id := hex.EncodeToString(sum[:])
+	store := filepath.Join(dockerConfigDir(), "contexts")
+
+	f, err := os.Open(filepath.Join(store, "meta", id, "meta.json"))
+	if err != nil {
+		return nil, fmt.Errorf("could not find docker context '%s': %v", name, err)
+	}
+	defer f.Close()
+
+	var meta struct {
+		Endpoints map[string]struct {
+			Host          string
+			SkipTLSVerify bool
+		}
+	}
+	if err := json.NewDecoder(f).Decode(&meta); err != nil {
+		return nil, fmt.Errorf("could not read docker context '%s': %v", name, err)
+	}
+	dockerEndpoint, ok := meta.Endpoints["docker"]
+	if !ok || dockerEndpoint.Host == "" {
+		return nil, fmt.Errorf("docker context '%s' has no docker endpoint", name)
+	}
+
+	endpoint := &Endpoint{Host: dockerEndpoint.Host, Source: source + " " + name}
+	tlsDir := filepath.Join(store, "tls", id, "docker")
+	if _, err := os.Stat(filepath.Join(tlsDir, "cert.pem")); err == nil {
+		endpoint.TLSCert = filepath.Join(tlsDir, "cert.pem")
+		endpoint.TLSKey = filepath.Join(tlsDir, "key.pem")
+	}
+	if _, err := os.Stat(filepath.Join(tlsDir, "ca.pem")); err == nil && !dockerEndpoint.SkipTLSVerify {
+		endpoint.TLSCACert = filepath.Join(tlsDir, "ca.pem")
+	}
+	endpoint.TLSSkipVerify = dockerEndpoint.SkipTLSVerify
+	return endpoint, nil
+}
+
+// SocketPath returns the path of the endpoint's unix socket, or "" if it
+// is reached over the network.
+func (e *Endpoint) SocketPath() string {
+	if strings.HasPrefix(e.Host, "unix://") {
+		return strings.TrimPrefix(e.Host, "unix://")
+	}
+	return ""
+}
+
+// NewClient connects to the endpoint. Over the network, it uses the same
+// transport and TLS configuration as the checks' own API requests.
+func (e *Endpoint) NewClient() (*docker.Client, error) {
+	if strings.HasPrefix(e.Host, "ssh://") || strings.HasPrefix(e.Host, "npipe://") {
+		return nil, fmt.Errorf("unsupported docker host '%s'", e.Host)
+	}
+	if e.SocketPath() != "" {
+		// go-dockerclient dials unix sockets itself
+		return docker.NewClient(e.Host)
+	}
+
+	host := e.Host
+	if e.usesTLS() {
+		if i := strings.Index(host, "://"); i >= 0 {
+			host = host[i+len("://"):]
+		}
+		host = "https://" + host
+	}
+	client, err := docker.NewClient(host)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := e.transport()
+	if err != nil {
+		return nil, err
+	}
+	client.HTTPClient = &http.Client{Transport: transport, Timeout: e.Timeout}
+	// attaching to a container dials the daemon with this
+	client.TLSConfig = transport.TLSClientConfig
+	return client, nil
+}
+
+func (e *Endpoint) usesTLS() bool {
+	return e.TLSCert != "" || e.TLSCACert != "" || e.TLSSkipVerify
+}
+
+// transport is how requests reach the endpoint over the network.
+func (e *Endpoint) transport() (*http.Transport, error) {
+	transport := &http.Transport{Proxy: http.ProxyFromEnvironment}
+	if e.usesTLS() {
+		config, err := e.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = config
+	}
+	return transport, nil
+}
+
+// tlsConfig authenticates with the endpoint's client certificate, if it
+// has one, and verifies the daemon's certificate with its CA certificate
+// or the system's, unless it is told not to.
+func (e *Endpoint) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: e.TLSSkipVerify}
+	if e.TLSCert != "" {
+		cert, err := tls.LoadX509KeyPair(e.TLSCert, e.TLSKey)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	if e.TLSCACert != "" {
+		pem, err := ioutil.ReadFile(e.TLSCACert)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", e.TLSCACert)
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+// UseDockerEndpoint makes the checks talk to the daemon at `endpoint`,
+// and audit its socket and process if they are known.
+func UseDockerEndpoint(endpoint *Endpoint) error {
+	client, err := endpoint.NewClient()
+	if err != nil {
+		return err
+	}
+	UseDockerClient(client)
+	apiEndpoint = endpoint
+	if socket := endpoint.SocketPath(); socket != "" {
+		dockerSocket = socket
+	}
+	if endpoint.PidFile != "" {
+		daemonPidFile = endpoint.PidFile
+	}
+	return nil
+}
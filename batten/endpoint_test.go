@@ -0,0 +1,247 @@
+package batten
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setEnv sets environment variables for the duration of a test, and
+// returns a function that restores them.
+func setEnv(vars map[string]string) func() {
+	type saved struct {
+		value string
+		set   bool
+	}
+	restore := make(map[string]saved)
+	for name, value := range vars {
+		old, set := os.LookupEnv(name)
+		restore[name] = saved{old, set}
+		os.Setenv(name, value)
+	}
+	return func() {
+		for name, old := range restore {
+			if old.set {
+				os.Setenv(name, old.value)
+			} else {
+				os.Unsetenv(name)
+			}
+		}
+	}
+}
+
+func contextID(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeContext(t *testing.T, configDir string, name string, meta string, withTLS bool) {
+	id := contextID(name)
+
+	metaDir := filepath.Join(configDir, "contexts", "meta", id)
+	if err := os.MkdirAll(metaDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(metaDir, "meta.json"), []byte(meta), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if withTLS {
+		tlsDir := filepath.Join(configDir, "contexts", "tls", id, "docker")
+		if err := os.MkdirAll(tlsDir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range []string{"ca.pem", "cert.pem", "key.pem"} {
+			if err := ioutil.WriteFile(filepath.Join(tlsDir, f), nil, 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestResolveEndpoint(t *testing.T) {
+	configDir, err := ioutil.TempDir("", "batten-docker-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(configDir)
+
+	writeContext(t, configDir, "prod", `{"Name":"prod","Endpoints":{"docker":{"Host":"tcp://prod:2376","SkipTLSVerify":false}}}`, true)
+	writeContext(t, configDir, "lab", `{"Name":"lab","Endpoints":{"docker":{"Host":"tcp://lab:2376","SkipTLSVerify":true}}}`, true)
+	prodTLS := filepath.Join(configDir, "contexts", "tls", contextID("prod"), "docker")
+	labTLS := filepath.Join(configDir, "contexts", "tls", contextID("lab"), "docker")
+
+	tests := []struct {
+		name    string
+		host    string
+		context string
+		env     map[string]string
+		want    Endpoint
+	}{
+		{
+			name: "flag wins over environment",
+			host: "tcp://flag:2375",
+			env:  map[string]string{"DOCKER_HOST": "tcp://env:2375"},
+			want: Endpoint{Host: "tcp://flag:2375", Source: "--host"},
+		},
+		{
+			name: "DOCKER_HOST with TLS verification",
+			env:  map[string]string{"DOCKER_HOST": "tcp://env:2376", "DOCKER_TLS_VERIFY": "1", "DOCKER_CERT_PATH": "/certs"},
+			want: Endpoint{Host: "tcp://env:2376", TLSCACert: "/certs/ca.pem", TLSCert: "/certs/cert.pem", TLSKey: "/certs/key.pem", Source: "DOCKER_HOST"},
+		},
+		{
+			name: "DOCKER_TLS without verification",
+			env:  map[string]string{"DOCKER_HOST": "tcp://env:2376", "DOCKER_TLS": "1", "DOCKER_CERT_PATH": "/certs"},
+			want: Endpoint{Host: "tcp://env:2376", TLSCert: "/certs/cert.pem", TLSKey: "/certs/key.pem", TLSSkipVerify: true, Source: "DOCKER_HOST"},
+		},
+		{
+			name:    "context flag wins over DOCKER_HOST",
+			context: "prod",
+			env:     map[string]string{"DOCKER_HOST": "tcp://env:2375"},
+			want: Endpoint{Host: "tcp://prod:2376", TLSCACert: filepath.Join(prodTLS, "ca.pem"),
+				TLSCert: filepath.Join(prodTLS, "cert.pem"), TLSKey: filepath.Join(prodTLS, "key.pem"), Source: "--context prod"},
+		},
+		{
+			name: "DOCKER_CONTEXT skipping TLS verification",
+			env:  map[string]string{"DOCKER_CONTEXT": "lab"},
+			want: Endpoint{Host: "tcp://lab:2376", TLSCert: filepath.Join(labTLS, "cert.pem"),
+				TLSKey: filepath.Join(labTLS, "key.pem"), TLSSkipVerify: true, Source: "DOCKER_CONTEXT lab"},
+		},
+	}
+
+	for _, test := range tests {
+		env := map[string]string{
+			"DOCKER_CONFIG":     configDir,
+			"DOCKER_HOST":       "",
+			"DOCKER_CONTEXT":    "",
+			"DOCKER_TLS":        "",
+			"DOCKER_TLS_VERIFY": "",
+			"DOCKER_CERT_PATH":  "",
+		}
+		for name, value := range test.env {
+			env[name] = value
+		}
+		restore := setEnv(env)
+
+		endpoint, err := ResolveEndpoint(test.host, test.context)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		} else if *endpoint != test.want {
+			t.Errorf("%s: got %+v, expected %+v", test.name, *endpoint, test.want)
+		}
+		restore()
+	}
+
+	restore := setEnv(map[string]string{"DOCKER_CONFIG": configDir, "DOCKER_HOST": "", "DOCKER_CONTEXT": "missing"})
+	defer restore()
+	if _, err := ResolveEndpoint("", ""); err == nil {
+		t.Errorf("expected an error for a missing context")
+	}
+}
+
+// The socket checks audit the socket of the endpoint in use, without
+// changing their definitions.
+func TestSocketChecksAuditEndpointSocket(t *testing.T) {
+	root, err := ioutil.TempDir("", "batten-host")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	SetHostRoot(root)
+	defer SetHostRoot("/")
+
+	socket := "/run/user/1000/docker.sock"
+	if err := os.MkdirAll(filepath.Join(root, filepath.Dir(socket)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, socket), nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(root, socket), 0666); err != nil {
+		t.Fatal(err)
+	}
+	defer func(saved string) { dockerSocket = saved }(dockerSocket)
+	dockerSocket = socket
+
+	check := makeDockerSocketFilePermsCheck().(*DockerSocketFilePermsCheck)
+	if ok, err := check.AuditCheck(); ok || err != nil {
+		t.Errorf("expected %s to fail the check, got %v, %v", socket, ok, err)
+	}
+	if check.filepath != DefaultDockerSocket {
+		t.Errorf("the check's socket changed to %s", check.filepath)
+	}
+}
+
+func TestEndpointTLS(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	}))
+	// the handshakes that are meant to fail are not worth logging
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "batten-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// the server's certificate doubles as the client's, and as the CA
+	// that signed it
+	cert := server.TLS.Certificates[0]
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	host := "tcp://" + strings.TrimPrefix(server.URL, "https://")
+	tests := []struct {
+		name     string
+		endpoint Endpoint
+		ok       bool
+	}{
+		{"CA certificate", Endpoint{Host: host, TLSCACert: certFile}, true},
+		{"client certificate and CA", Endpoint{Host: host, TLSCACert: certFile, TLSCert: certFile, TLSKey: keyFile}, true},
+		// without a CA certificate, the system's do not know the server's
+		{"client certificate only", Endpoint{Host: host, TLSCert: certFile, TLSKey: keyFile}, false},
+		{"skipping verification", Endpoint{Host: host, TLSCert: certFile, TLSKey: keyFile, TLSSkipVerify: true}, true},
+	}
+	for _, test := range tests {
+		client, err := test.endpoint.NewClient()
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if err := client.Ping(); (err == nil) != test.ok {
+			t.Errorf("%s: docker client got %v", test.name, err)
+		}
+
+		httpClient, base, err := test.endpoint.httpClient()
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		resp, err := httpClient.Get(base + "/_ping")
+		if err == nil {
+			resp.Body.Close()
+		}
+		if (err == nil) != test.ok {
+			t.Errorf("%s: API requests got %v", test.name, err)
+		}
+	}
+}
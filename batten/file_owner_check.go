@@ -19,6 +19,9 @@ type FileOwnerCheck struct {
 }
 
 func lookupUid(username string) (uint32, error) {
+	if hostRoot != "/" {
+		return lookupHostUid(username)
+	}
 	u, err := user.Lookup(username)
 	if err != nil {
 		return 0, err
@@ -27,9 +30,27 @@ func lookupUid(username string) (uint32, error) {
 	return uint32(uid), err
 }
 
+// lookupHostUid finds `username` in the audited host's /etc/passwd, which
+// is not the one the os/user package would consult when the host is
+// mounted into a scan container.
+func lookupHostUid(username string) (uint32, error) {
+	bytes, err := ioutil.ReadFile(hostPath("/etc/passwd"))
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(string(bytes), "\n") {
+		items := strings.Split(line, ":")
+		if len(items) >= 3 && items[0] == username {
+			uid, err := strconv.Atoi(items[2])
+			return uint32(uid), err
+		}
+	}
+	return 0, user.UnknownUserError(username)
+}
+
 func getGroups() (res map[string]uint32, err error) {
 	res = make(map[string]uint32, 0)
-	bytes, err := ioutil.ReadFile("/etc/group")
+	bytes, err := ioutil.ReadFile(hostPath("/etc/group"))
 	if err != nil {
 		return nil, err
 	}
@@ -57,7 +78,7 @@ func lookupGid(groupname string) (uint32, error) {
 }
 
 func isOwner(filepath string, uid uint32) (bool, error) {
-	fi, err := os.Stat(filepath)
+	fi, err := os.Stat(hostPath(filepath))
 	if err != nil {
 		return false, err
 	}
@@ -68,7 +89,7 @@ func isOwner(filepath string, uid uint32) (bool, error) {
 }
 
 func isGroupOwner(filepath string, gid uint32) (bool, error) {
-	fi, err := os.Stat(filepath)
+	fi, err := os.Stat(hostPath(filepath))
 	if err != nil {
 		return false, err
 	}
@@ -103,7 +124,7 @@ func (fo *FileOwnerCheck) IsOwnerAndGroupOwnerRecursive(uid uint32, gid uint32)
 		return succ, err
 	}
 
-	files, err := ioutil.ReadDir(fo.filepath)
+	files, err := ioutil.ReadDir(hostPath(fo.filepath))
 	for _, file := range files {
 		fullpath := path.Join(fo.filepath, file.Name())
 		succ, err := isOwnerAndGroupOwner(fullpath, uid, gid)
@@ -14,7 +14,7 @@ type FilePermsCheck struct {
 }
 
 func (fo *FilePermsCheck) HasPerms(targetMode os.FileMode) (bool, error) {
-	fi, err := os.Stat(fo.filepath)
+	fi, err := os.Stat(hostPath(fo.filepath))
 	if err != nil {
 		return false, err
 	}
@@ -24,7 +24,7 @@ func (fo *FilePermsCheck) HasPerms(targetMode os.FileMode) (bool, error) {
 }
 
 func atLeastPerms(filepath string, targetMode os.FileMode) (bool, error) {
-	fi, err := os.Stat(filepath)
+	fi, err := os.Stat(hostPath(filepath))
 	if err != nil {
 		return false, err
 	}
@@ -48,7 +48,7 @@ func (fo *FilePermsCheck) HasAtLeastPermsRecursive(targetMode os.FileMode) (bool
 		return succ, err
 	}
 
-	files, err := ioutil.ReadDir(fo.filepath)
+	files, err := ioutil.ReadDir(hostPath(fo.filepath))
 	for _, file := range files {
 		fullpath := path.Join(fo.filepath, file.Name())
 		succ, err := atLeastPerms(fullpath, targetMode)
@@ -60,8 +60,8 @@ func (fo *FilePermsCheck) HasAtLeastPermsRecursive(targetMode os.FileMode) (bool
 	return true, nil
 }
 
-func (fo *FilePermsCheck) validateFromArgs(lookForFlag string, args []string) (bool, error) {
-	filepath := getArgValue(lookForFlag, args)
+// validatePath checks the permissions of `filepath`, if a path was given.
+func (fo *FilePermsCheck) validatePath(filepath string) (bool, error) {
 
 	if filepath != "" {
 		if PathExists(filepath) {
@@ -0,0 +1,231 @@
+package batten
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Policy is what the checks allow that batten cannot know by itself, read
+// from YAML with `batten check --policy`:
+//
+//	capabilities:
+//	  allowed: [NET_BIND_SERVICE]
+//	  containers:
+//	    - image: registry.example.com/cni/*
+//	      allowed: [NET_ADMIN, NET_RAW]
+//	namespaces:
+//	  containers:
+//	    - image: prom/node-exporter:*
+//	      allowed: [network, pid]
+//	ports:
+//	  interfaces: [10.0.0.5]
+//	  containers:
+//	    - name: ingress
+//	      allowed: [80, 443]
+//	rootfs:
+//	  containers:
+//	    - image: legacy/*
+//	logging:
+//	  driver: syslog
+//	  allowed: [syslog, gelf]
+//
+// Sections left out keep the checks' own defaults.
+type Policy struct {
+	Capabilities *CapabilityPolicy `yaml:"capabilities"`
+	Namespaces   *NamespacePolicy  `yaml:"namespaces"`
+	Ports        *PortPolicy       `yaml:"ports"`
+	Rootfs       *RootfsPolicy     `yaml:"rootfs"`
+	Logging      *LoggingPolicy    `yaml:"logging"`
+}
+
+// ContainerPattern selects containers by the image they run and their
+// name, as matched by path.Match. A pattern left empty matches any.
+type ContainerPattern struct {
+	Image string `yaml:"image"`
+	Name  string `yaml:"name"`
+}
+
+// CapabilityPolicy lists the capabilities every container may have, and
+// those containers whose image or name matches a rule may have too.
+type CapabilityPolicy struct {
+	Allowed    []string         `yaml:"allowed"`
+	Containers []CapabilityRule `yaml:"containers"`
+}
+
+// CapabilityRule allows capabilities to the containers it matches.
+type CapabilityRule struct {
+	ContainerPattern `yaml:",inline"`
+	Allowed          []string `yaml:"allowed"`
+}
+
+// NamespacePolicy lists the containers that may share namespaces of the
+// host, such as monitoring agents or CNI plugins.
+type NamespacePolicy struct {
+	Containers []NamespaceRule `yaml:"containers"`
+}
+
+// NamespaceRule allows the containers it matches to share the host
+// namespaces it names, of hostNamespaces.
+type NamespaceRule struct {
+	ContainerPattern `yaml:",inline"`
+	Allowed          []string `yaml:"allowed"`
+}
+
+// PortPolicy lists the host addresses containers must publish their ports
+// on, if any, and the host ports containers matching a rule may publish
+// whatever the port checks say.
+type PortPolicy struct {
+	Interfaces []string   `yaml:"interfaces"`
+	Containers []PortRule `yaml:"containers"`
+}
+
+// PortRule allows the containers it matches to publish host ports.
+type PortRule struct {
+	ContainerPattern `yaml:",inline"`
+	Allowed          []int `yaml:"allowed"`
+}
+
+// RootfsPolicy lists the containers that may write to their root
+// filesystem, and execute from their tmpfs mounts.
+type RootfsPolicy struct {
+	Containers []ContainerPattern `yaml:"containers"`
+}
+
+// LoggingPolicy lists the log drivers that send logs to a central
+// collector, and the approved one every container must log with. Without
+// an approved driver, it is the daemon's default one.
+type LoggingPolicy struct {
+	Driver  string   `yaml:"driver"`
+	Allowed []string `yaml:"allowed"`
+}
+
+// policyCheck is implemented by checks that take part of their policy
+// from a Policy.
+type policyCheck interface {
+	usePolicy(policy *Policy)
+}
+
+// ReadPolicy reads and validates a policy file.
+func ReadPolicy(filename string) (*Policy, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	policy := &Policy{}
+	if err := yaml.UnmarshalStrict(data, policy); err != nil {
+		return nil, err
+	}
+
+	if caps := policy.Capabilities; caps != nil {
+		if err := validateCapabilities(caps.Allowed); err != nil {
+			return nil, fmt.Errorf("capabilities: %v", err)
+		}
+		for i, rule := range caps.Containers {
+			if err := rule.validate(); err != nil {
+				return nil, fmt.Errorf("capabilities: rule #%d %v", i+1, err)
+			}
+			if err := validateCapabilities(rule.Allowed); err != nil {
+				return nil, fmt.Errorf("capabilities: rule #%d: %v", i+1, err)
+			}
+		}
+	}
+
+	if namespaces := policy.Namespaces; namespaces != nil {
+		for i, rule := range namespaces.Containers {
+			if err := rule.validate(); err != nil {
+				return nil, fmt.Errorf("namespaces: rule #%d %v", i+1, err)
+			}
+			for _, ns := range rule.Allowed {
+				if _, ok := hostNamespaces[ns]; !ok {
+					return nil, fmt.Errorf("namespaces: rule #%d: unknown namespace '%s'", i+1, ns)
+				}
+			}
+		}
+	}
+	if ports := policy.Ports; ports != nil {
+		for _, address := range ports.Interfaces {
+			if net.ParseIP(address) == nil {
+				return nil, fmt.Errorf("ports: '%s' is not an IP address", address)
+			}
+		}
+		for i, rule := range ports.Containers {
+			if err := rule.validate(); err != nil {
+				return nil, fmt.Errorf("ports: rule #%d %v", i+1, err)
+			}
+			for _, port := range rule.Allowed {
+				if port < 1 || port > 65535 {
+					return nil, fmt.Errorf("ports: rule #%d: invalid port %d", i+1, port)
+				}
+			}
+		}
+	}
+	if rootfs := policy.Rootfs; rootfs != nil {
+		for i, pattern := range rootfs.Containers {
+			if err := pattern.validate(); err != nil {
+				return nil, fmt.Errorf("rootfs: rule #%d %v", i+1, err)
+			}
+		}
+	}
+	if logging := policy.Logging; logging != nil {
+		if len(logging.Allowed) == 0 && logging.Driver != "" {
+			logging.Allowed = []string{logging.Driver}
+		}
+		if len(logging.Allowed) == 0 {
+			return nil, fmt.Errorf("logging: no 'driver' or 'allowed' log drivers")
+		}
+		if logging.Driver != "" && !stringInSlice(logging.Driver, logging.Allowed) {
+			return nil, fmt.Errorf("logging: the approved driver '%s' is not allowed", logging.Driver)
+		}
+	}
+	return policy, nil
+}
+
+func (p *ContainerPattern) validate() error {
+	if p.Image == "" && p.Name == "" {
+		return fmt.Errorf("has no 'image' or 'name' pattern")
+	}
+	for _, pattern := range []string{p.Image, p.Name} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("has a bad pattern '%s'", pattern)
+		}
+	}
+	return nil
+}
+
+func validateCapabilities(caps []string) error {
+	for _, c := range caps {
+		name := capabilityName(c)
+		if name != "ALL" && !stringInSlice(name, allCapabilities) {
+			return fmt.Errorf("unknown capability '%s'", c)
+		}
+	}
+	return nil
+}
+
+// UsePolicy makes the checks follow `policy`.
+func UsePolicy(policy *Policy) {
+	for _, check := range Checks {
+		if c, ok := check.(policyCheck); ok {
+			c.usePolicy(policy)
+		}
+	}
+}
+
+// matches tells whether the pattern selects the container named `name`
+// running `image`.
+func (p *ContainerPattern) matches(image string, name string) bool {
+	for _, m := range []struct{ pattern, value string }{{p.Image, image}, {p.Name, strings.TrimPrefix(name, "/")}} {
+		if m.pattern == "" {
+			continue
+		}
+		if ok, _ := path.Match(m.pattern, m.value); !ok {
+			return false
+		}
+	}
+	return true
+}
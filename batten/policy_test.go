@@ -0,0 +1,65 @@
+package batten
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestReadPolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "batten-policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		data string
+		err  string
+	}{
+		{"capabilities:\n  allowed: [net_bind_service]\n  containers:\n    - name: agent-*\n      allowed: [CAP_SYS_PTRACE]\n", ""},
+		{"", ""},
+		{"capabilities:\n  allowed: [NET_BIND]\n", "unknown capability 'NET_BIND'"},
+		{"capabilities:\n  containers:\n    - allowed: [NET_ADMIN]\n", "rule #1 has no 'image' or 'name' pattern"},
+		{"namespaces:\n  containers:\n    - image: node-exporter\n      allowed: [net]\n", "unknown namespace 'net'"},
+		{"capabilities:\n  containers:\n    - image: 'cni/[*'\n", "bad pattern"},
+		{"ports:\n  interfaces: [eth0]\n", "'eth0' is not an IP address"},
+		{"ports:\n  containers:\n    - name: ingress\n      allowed: [0]\n", "invalid port 0"},
+		{"capability:\n  allowed: []\n", "not found"},
+		{"logging:\n  driver: syslog\n", ""},
+		{"logging:\n  driver: syslog\n  allowed: [gelf, fluentd]\n", "the approved driver 'syslog' is not allowed"},
+		{"logging: {}\n", "no 'driver' or 'allowed' log drivers"},
+	}
+	for _, test := range tests {
+		filename := path.Join(dir, "policy.yaml")
+		if err := ioutil.WriteFile(filename, []byte(test.data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := ReadPolicy(filename)
+		if test.err == "" && err != nil {
+			t.Errorf("%q: unexpected error %v", test.data, err)
+		} else if test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)) {
+			t.Errorf("%q: expected error %q, got %v", test.data, test.err, err)
+		}
+	}
+}
+
+func TestContainerPatternMatches(t *testing.T) {
+	pattern := &ContainerPattern{Image: "registry.example.com/cni/*", Name: "calico-*"}
+	tests := []struct {
+		image   string
+		name    string
+		matches bool
+	}{
+		{"registry.example.com/cni/calico:v3", "/calico-node", true},
+		{"registry.example.com/cni/calico:v3", "/web", false},
+		{"calico:v3", "/calico-node", false},
+	}
+	for _, test := range tests {
+		if pattern.matches(test.image, test.name) != test.matches {
+			t.Errorf("%s %s: expected match to be %v", test.image, test.name, test.matches)
+		}
+	}
+}
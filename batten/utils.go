@@ -2,12 +2,10 @@ package batten
 
 import (
 	"errors"
-	"fmt"
-	"io/ioutil"
 	"os"
 	"os/exec"
+	"path"
 	"strconv"
-	"strings"
 
 	docker "github.com/fsouza/go-dockerclient"
 	"github.com/jandre/procfs"
@@ -18,16 +16,43 @@ const (
 	DockerPidFile    = "/var/run/docker.pid"
 )
 
+var dockerClient *docker.Client
+
+// hostRoot is where the audited host's root filesystem can be found. It
+// is "/" unless batten runs in a container with the host mounted into it.
+var hostRoot = "/"
+
+// SetHostRoot makes the checks audit the host whose root filesystem is
+// mounted at `root`, instead of the filesystem batten runs in.
+func SetHostRoot(root string) {
+	hostRoot = root
+}
+
+// hostPath returns where the host's `filename` can be found from here.
+func hostPath(filename string) string {
+	return path.Join(hostRoot, filename)
+}
+
+// UseDockerClient makes every check that talks to the Docker API
+// use `client` instead of the local unix socket.
+func UseDockerClient(client *docker.Client) {
+	dockerClient = client
+	apiEndpoint = nil
+}
+
 func getDockerAPIConnection() (*docker.Client, error) {
-	return docker.NewClient(DockerUnixSocket)
+	if dockerClient != nil {
+		return dockerClient, nil
+	}
+	return localEndpoint().NewClient()
 }
 
 //
-// PathExists return true if `filename` exists
+// PathExists return true if `filename` exists on the audited host
 //
 func PathExists(filename string) bool {
 
-	_, err := os.Stat(filename)
+	_, err := os.Stat(hostPath(filename))
 
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -65,29 +90,11 @@ func runAuditCtl() (string, error) {
 }
 
 func pidOfDocker(dockerPidFile string) (int, error) {
-
-	if dockerPidFile == "" {
-		dockerPidFile = DockerPidFile
-	}
-	if !PathExists(dockerPidFile) {
-		// no path exists
-		return 0, nil
-	}
-
-	bytes, err := ioutil.ReadFile(dockerPidFile)
-
-	if err != nil {
-		// TODO: log error message
+	daemon, err := findDaemon(dockerPidFile)
+	if err != nil || daemon == nil {
 		return 0, err
 	}
-	pid, err := strconv.Atoi(string(bytes))
-
-	procFile := fmt.Sprintf("/proc/%d", pid)
-
-	if PathExists(procFile) {
-		return pid, nil
-	}
-	return 0, nil
+	return daemon.Pid, nil
 }
 
 func getDockerProcess(dockerPidFile string) (*procfs.Process, error) {
@@ -96,65 +103,33 @@ func getDockerProcess(dockerPidFile string) (*procfs.Process, error) {
 		return nil, err
 	}
 
-	return procfs.NewProcess(pid, true)
+	return procfs.NewProcessFromPath(pid, path.Join(procRoot, strconv.Itoa(pid)), true)
 }
 
 func readDockerDaemonEnviron(dockerPidFile string) (succ bool, environ map[string]string, err error) {
 
-	pid, err := pidOfDocker(dockerPidFile)
+	daemon, err := findDaemon(dockerPidFile)
 	if err != nil {
-		// TODO: log error message
 		return false, environ, err
 	}
-	if pid <= 0 {
-		// no pid was found, but not really an error
+	if daemon == nil {
+		// no daemon was found, but not really an error
 		return true, environ, nil
 	}
 
-	process, err := procfs.NewProcess(pid, true)
-	if err != nil {
-		return false, environ, err
-	}
-
-	environ = process.Environ
-	return true, environ, nil
+	return true, daemon.environ, nil
 }
 
 func readDockerDaemonArgs(dockerPidFile string) (succ bool, cmdLine []string, err error) {
 
-	pid, err := pidOfDocker(dockerPidFile)
+	daemon, err := findDaemon(dockerPidFile)
 	if err != nil {
-		// TODO: log error message
 		return false, cmdLine, err
 	}
-	if pid <= 0 {
-		// no pid was found, but not really an error
+	if daemon == nil {
+		// no daemon was found, but not really an error
 		return true, cmdLine, nil
 	}
-	process, err := procfs.NewProcess(pid, true)
-	if err != nil {
-		return false, cmdLine, err
-	}
-
-	cmdLine = process.Cmdline
-	return true, cmdLine, nil
-}
-
-func getArgValue(lookFor string, args []string) string {
-	prev := ""
-	for _, arg := range args {
-		arg = strings.TrimSpace(arg)
-		if arg != "" {
-			if prev == lookFor {
-				return arg
-			} else if strings.HasPrefix(arg, lookFor+"=") {
-				filename := arg[len(lookFor)+1:]
-				return filename
-			}
-			prev = arg
-
-		}
-	}
 
-	return ""
+	return true, daemon.Args, nil
 }
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dockersecuritytools/batten/batten"
+	"github.com/dockersecuritytools/batten/cli"
+	docker "github.com/fsouza/go-dockerclient"
+	dtesting "github.com/fsouza/go-dockerclient/testing"
+)
+
+// TestCheckJSONOutput runs every check the way a scan container does,
+// with `check --format=json`, and decodes what it writes to stdout. A
+// remote scan reads its report from there, so nothing else may be
+// written to it.
+func TestCheckJSONOutput(t *testing.T) {
+	server, err := dtesting.NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client, err := docker.NewClient(strings.TrimSuffix(server.URL(), "/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// images that are not trusted
+	for _, image := range []string{"nginx:latest", "busybox"} {
+		if err := client.PullImage(docker.PullImageOptions{Repository: image}, docker.AuthConfiguration{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	root, err := ioutil.TempDir("", "batten-host")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	if err := batten.UseDockerEndpoint(&batten.Endpoint{Host: strings.TrimSuffix(server.URL(), "/")}); err != nil {
+		t.Fatal(err)
+	}
+	defer batten.UseDockerClient(nil)
+	batten.SetHostRoot(root)
+	defer batten.SetHostRoot("/")
+
+	format, output := *checkFormat, *checkOutput
+	*checkFormat, *checkOutput = "json", ""
+	defer func() { *checkFormat, *checkOutput = format, output }()
+
+	stdout, stderr := os.Stdout, os.Stderr
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	devnull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devnull.Close()
+	os.Stdout, os.Stderr = w, devnull
+	read := make(chan []byte)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		read <- buf.Bytes()
+	}()
+	runChecks("test", batten.RunCheck)
+	w.Close()
+	os.Stdout, os.Stderr = stdout, stderr
+	out := <-read
+
+	report, err := cli.ReadReport(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("%v:\n%s", err, out)
+	}
+	if len(report.Results) != len(batten.Checks) {
+		t.Errorf("expected %d results, got %d", len(batten.Checks), len(report.Results))
+	}
+	if rest := bytes.TrimSpace(out[bytes.LastIndex(out, []byte("\n}"))+2:]); len(rest) > 0 {
+		t.Errorf("unexpected output after the report: %s", rest)
+	}
+}
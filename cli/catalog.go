@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dockersecuritytools/batten/batten"
+	"github.com/mgutz/ansi"
+	"github.com/olekukonko/tablewriter"
+)
+
+// FormatCheckListForConsole prints a table with the identifier,
+// section, name, level and severity of every check in `checks`.
+func FormatCheckListForConsole(checks []batten.Check) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"ID", "Section", "Name", "Level", "Severity"})
+	table.SetBorder(false)
+	table.SetColWidth(60)
+
+	for _, check := range checks {
+		checkdefinition := check.GetCheckDefinition()
+		table.Append([]string{
+			checkdefinition.Identifier(),
+			checkdefinition.Category(),
+			checkdefinition.Name(),
+			strconv.Itoa(checkdefinition.Level()),
+			checkdefinition.Severity(),
+		})
+	}
+
+	table.Render()
+}
+
+// FormatCheckDefinitionForConsole prints everything batten knows
+// about a check, so it can be read up on before it is run.
+func FormatCheckDefinitionForConsole(checkdefinition batten.CheckDefinition) {
+	fmt.Printf("[%s] %s\n", checkdefinition.Identifier(), checkdefinition.Name())
+	fmt.Printf("%sSection:%s %s\n", ansi.LightWhite, reset, checkdefinition.Category())
+	fmt.Printf("%sLevel:%s %d\n", ansi.LightWhite, reset, checkdefinition.Level())
+	fmt.Printf("%sSeverity:%s %s\n\n", ansi.LightWhite, reset, checkdefinition.Severity())
+
+	printSection("Description", checkdefinition.Description())
+	printSection("Rationale", checkdefinition.Rationale())
+	printSection("Impact", checkdefinition.Impact())
+	printSection("Default Value", checkdefinition.DefaultValue())
+	printSection("Audit", checkdefinition.AuditDescription())
+	printSection("Remediation", checkdefinition.Remediation())
+	printSection("References", strings.Join(checkdefinition.References(), "\n"))
+}
+
+func printSection(title string, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+
+	fmt.Println(ansi.LightWhite + title + reset)
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Println("    " + strings.TrimSpace(line))
+	}
+	fmt.Println()
+}
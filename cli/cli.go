@@ -2,7 +2,10 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"regexp"
+
 	"github.com/dockersecuritytools/batten/batten"
 	"github.com/mgutz/ansi"
 	"github.com/olekukonko/tablewriter"
@@ -20,38 +23,92 @@ var (
 var resultsError = red + "FAILED (error)" + reset
 var resultsFailed = red + "FAILED" + reset
 var resultsOK = lime + "PASSED" + reset
+var resultsNotApplicable = yellow + "N/A" + reset
 
 //
-// FormatResultsForConsole formats the `CheckResults` for
+// FormatResultsForConsole formats the `CheckResults` of the
+// check `idx` out of `total` for console display.
+//
+func FormatResultsForConsole(idx int, total int, results *batten.CheckResults) {
+	formatEntryForConsole(os.Stdout, idx, total, NewReportEntry(results))
+}
+
+//
+// FormatReportForConsole formats a whole `Report` for
 // console display.
 //
-func FormatResultsForConsole(idx int, results *batten.CheckResults) {
-
-	checkdefinition := results.CheckDefinition
-
-	fmt.Printf("[%d/%d] ", idx+1, len(batten.Checks))
-
-	if results.Error != nil {
-		fmt.Printf("%s [%s] %s\n", resultsError, checkdefinition.Identifier(), checkdefinition.Name())
-		fmt.Println("\t There was an error executing the check:", results.Error)
-	} else {
-		if results.Success {
-			fmt.Printf("%s [%s] %s\n", resultsOK, checkdefinition.Identifier(), checkdefinition.Name())
-		} else {
-			fmt.Printf("%s [%s] %s\n", resultsFailed, checkdefinition.Identifier(), checkdefinition.Name())
-			table := tablewriter.NewWriter(os.Stdout)
-			table.SetBorder(false)
-			table.SetColWidth(75)
-			table.Append([]string{
-				ansi.LightWhite + "Description" + reset,
-				checkdefinition.Description(),
-			})
-			table.Append([]string{
-				ansi.LightWhite + "Remediation" + reset,
-				checkdefinition.Remediation(),
-			})
-
-			table.Render()
+func FormatReportForConsole(w io.Writer, report *Report) {
+	for i, entry := range report.Results {
+		formatEntryForConsole(w, i, len(report.Results), entry)
+	}
+}
+
+//
+// FormatProgressForConsole writes a one line status of the
+// `CheckResults` of the check `idx` out of `total` to `w`, to
+// follow a scan while it runs.
+//
+func FormatProgressForConsole(w io.Writer, idx int, total int, results *batten.CheckResults) {
+	formatStatusLine(w, idx, total, NewReportEntry(results))
+}
+
+func formatStatusLine(w io.Writer, idx int, total int, entry ReportEntry) {
+	status := resultsFailed
+	switch entry.Status {
+	case StatusError:
+		status = resultsError
+	case StatusPassed:
+		status = resultsOK
+	case StatusNotApplicable:
+		status = resultsNotApplicable
+	}
+	fmt.Fprintf(w, "[%d/%d] %s [%s] %s\n", idx+1, total, status, entry.Identifier, entry.Name)
+}
+
+func formatEntryForConsole(w io.Writer, idx int, total int, entry ReportEntry) {
+
+	formatStatusLine(w, idx, total, entry)
+
+	switch entry.Status {
+	case StatusError:
+		fmt.Fprintln(w, "\t There was an error executing the check:", entry.Error)
+	case StatusFailed:
+		table := tablewriter.NewWriter(w)
+		table.SetBorder(false)
+		table.SetColWidth(75)
+		table.Append([]string{
+			ansi.LightWhite + "Description" + reset,
+			entry.Description,
+		})
+		table.Append([]string{
+			ansi.LightWhite + "Remediation" + reset,
+			entry.Remediation,
+		})
+
+		table.Render()
+
+		for _, finding := range entry.Findings {
+			fmt.Fprintln(w, "\t -", finding)
 		}
 	}
 }
+
+// ansiEscape matches the escape sequences that color console output.
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// NewPlainWriter returns a writer to `w` that drops the colors of console
+// output, for output that does not go to a terminal.
+func NewPlainWriter(w io.Writer) io.Writer {
+	return plainWriter{w}
+}
+
+type plainWriter struct {
+	w io.Writer
+}
+
+func (p plainWriter) Write(b []byte) (int, error) {
+	if _, err := p.w.Write(ansiEscape.ReplaceAll(b, nil)); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dockersecuritytools/batten/batten"
+)
+
+// FormatDaemonsForConsole lists the Docker daemons and containerd
+// processes found on the host.
+func FormatDaemonsForConsole(w io.Writer, daemons []*batten.DaemonProcess, containerd []*batten.ContainerdProcess) {
+	if len(daemons) == 0 {
+		fmt.Fprintln(w, "No Docker daemon is running.")
+	}
+	for _, d := range daemons {
+		fmt.Fprintln(w, d)
+		fmt.Fprintln(w, "\t command line:", strings.Join(d.Args, " "))
+		fmt.Fprintln(w, "\t pid file:    ", d.PidFile)
+		fmt.Fprintln(w, "\t data root:   ", d.DataRoot)
+		fmt.Fprintln(w, "\t config file: ", d.ConfigFile)
+		if d.Root != "" {
+			fmt.Fprintln(w, "\t files under: ", d.Root)
+		}
+	}
+
+	if len(containerd) == 0 {
+		fmt.Fprintln(w, "No containerd is running.")
+	}
+	for _, c := range containerd {
+		config := c.ConfigFile
+		if !c.ConfigExists {
+			config += " (missing, containerd runs with its defaults)"
+		}
+		fmt.Fprintf(w, "containerd (pid %d)\n", c.Pid)
+		fmt.Fprintln(w, "\t command line:", strings.Join(c.Args, " "))
+		fmt.Fprintln(w, "\t config file: ", config)
+		fmt.Fprintln(w, "\t address:     ", c.Address)
+	}
+}
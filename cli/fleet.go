@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mgutz/ansi"
+	"github.com/olekukonko/tablewriter"
+)
+
+const (
+	FleetScanned     = "scanned"
+	FleetUnreachable = "unreachable"
+	FleetError       = "error"
+)
+
+// FleetReport aggregates the scans of every host in an inventory.
+type FleetReport struct {
+	Version   string      `json:"version"`
+	Generated time.Time   `json:"generated"`
+	Hosts     []FleetHost `json:"hosts"`
+}
+
+// FleetHost is the outcome of scanning one host of a fleet. `Report` is
+// only set when the host was scanned.
+type FleetHost struct {
+	Name   string            `json:"name"`
+	Host   string            `json:"host"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Status string            `json:"status"`
+	Error  string            `json:"error,omitempty"`
+	Score  int               `json:"score"`
+	Report *Report           `json:"report,omitempty"`
+}
+
+// CheckFailures lists the hosts a check failed on.
+type CheckFailures struct {
+	Identifier string   `json:"identifier"`
+	Name       string   `json:"name"`
+	Hosts      []string `json:"hosts"`
+}
+
+// Score is the percentage of checks that passed, out of those that
+// were applicable to the host. Checks that could not be run count
+// against it.
+func Score(report *Report) int {
+	passed := report.Count(StatusPassed)
+	total := passed + report.Count(StatusFailed) + report.Count(StatusError)
+	if total == 0 {
+		return 0
+	}
+	return passed * 100 / total
+}
+
+// Count returns the number of hosts with the given status.
+func (r *FleetReport) Count(status string) int {
+	var count int
+	for _, host := range r.Hosts {
+		if host.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// MostFailed returns the checks that failed on at least one host, those
+// failing on the most hosts first.
+func (r *FleetReport) MostFailed() []CheckFailures {
+	var failures []CheckFailures
+	index := make(map[string]int)
+
+	for _, host := range r.Hosts {
+		if host.Report == nil {
+			continue
+		}
+		for _, entry := range host.Report.Results {
+			if entry.Status != StatusFailed {
+				continue
+			}
+			i, ok := index[entry.Identifier]
+			if !ok {
+				i = len(failures)
+				index[entry.Identifier] = i
+				failures = append(failures, CheckFailures{Identifier: entry.Identifier, Name: entry.Name})
+			}
+			failures[i].Hosts = append(failures[i].Hosts, host.Name)
+		}
+	}
+
+	sort.SliceStable(failures, func(i, j int) bool {
+		return len(failures[i].Hosts) > len(failures[j].Hosts)
+	})
+	return failures
+}
+
+// FleetFormats lists the output formats a fleet report can be rendered into.
+var FleetFormats = []string{"console", "json"}
+
+// WriteFleetReport renders `report` to `w` in the given format.
+func WriteFleetReport(w io.Writer, format string, report *FleetReport) error {
+	switch format {
+	case "console":
+		FormatFleetReportForConsole(w, report)
+		return nil
+	case "json":
+		return writeJSON(w, struct {
+			*FleetReport
+			MostFailed []CheckFailures `json:"most_failed"`
+		}{report, report.MostFailed()})
+	}
+	return fmt.Errorf("unknown fleet report format '%s'", format)
+}
+
+// FormatFleetReportForConsole prints a table with the score of every
+// host, the checks that failed on the most hosts, and the hosts that
+// could not be scanned.
+func FormatFleetReportForConsole(w io.Writer, report *FleetReport) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Name", "Host", "Status", "Score", "Passed", "Failed", "Errors"})
+	table.SetBorder(false)
+
+	for _, host := range report.Hosts {
+		if host.Report == nil {
+			table.Append([]string{host.Name, host.Host, host.Status, "-", "-", "-", "-"})
+			continue
+		}
+		table.Append([]string{
+			host.Name,
+			host.Host,
+			host.Status,
+			fmt.Sprintf("%d%%", host.Score),
+			fmt.Sprint(host.Report.Count(StatusPassed)),
+			fmt.Sprint(host.Report.Count(StatusFailed)),
+			fmt.Sprint(host.Report.Count(StatusError)),
+		})
+	}
+	table.Render()
+
+	if failures := report.MostFailed(); len(failures) > 0 {
+		fmt.Fprintf(w, "\n%sMost failed checks:%s\n", ansi.LightWhite, reset)
+		table = tablewriter.NewWriter(w)
+		table.SetHeader([]string{"ID", "Name", "Hosts"})
+		table.SetBorder(false)
+		table.SetColWidth(60)
+		for _, failure := range failures {
+			table.Append([]string{failure.Identifier, failure.Name, fmt.Sprint(len(failure.Hosts))})
+		}
+		table.Render()
+	}
+
+	var failed []string
+	for _, host := range report.Hosts {
+		if host.Status != FleetScanned {
+			failed = append(failed, fmt.Sprintf("  %s (%s): %s", host.Name, host.Host, host.Error))
+		}
+	}
+	if len(failed) > 0 {
+		fmt.Fprintf(w, "\n%sHosts not scanned:%s\n%s\n", ansi.Red, reset, strings.Join(failed, "\n"))
+	}
+
+	fmt.Fprintf(w, "\n%d hosts: %d scanned, %d unreachable, %d errors.\n", len(report.Hosts),
+		report.Count(FleetScanned), report.Count(FleetUnreachable), report.Count(FleetError))
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func reportWith(statuses map[string]string) *Report {
+	report := &Report{}
+	for id, status := range statuses {
+		report.Results = append(report.Results, ReportEntry{Identifier: id, Name: id, Status: status})
+	}
+	return report
+}
+
+func TestFleetMostFailed(t *testing.T) {
+	fleet := &FleetReport{Hosts: []FleetHost{
+		{Name: "a", Status: FleetScanned, Report: reportWith(map[string]string{"1.1": StatusFailed, "2.1": StatusPassed})},
+		{Name: "b", Status: FleetScanned, Report: reportWith(map[string]string{"1.1": StatusFailed, "2.1": StatusFailed})},
+		{Name: "c", Status: FleetUnreachable},
+		{Name: "d", Status: FleetScanned, Report: reportWith(map[string]string{"1.1": StatusFailed, "2.1": StatusError})},
+	}}
+
+	failures := fleet.MostFailed()
+	if len(failures) != 2 {
+		t.Fatalf("expected 2 failing checks, got %d", len(failures))
+	}
+	if failures[0].Identifier != "1.1" || !reflect.DeepEqual(failures[0].Hosts, []string{"a", "b", "d"}) {
+		t.Errorf("unexpected first failure: %+v", failures[0])
+	}
+	if failures[1].Identifier != "2.1" || !reflect.DeepEqual(failures[1].Hosts, []string{"b"}) {
+		t.Errorf("unexpected second failure: %+v", failures[1])
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		statuses map[string]string
+		score    int
+	}{
+		{map[string]string{}, 0},
+		{map[string]string{"1": StatusPassed, "2": StatusNotApplicable}, 100},
+		{map[string]string{"1": StatusPassed, "2": StatusFailed, "3": StatusError, "4": StatusPassed}, 50},
+	}
+	for _, test := range tests {
+		if score := Score(reportWith(test.statuses)); score != test.score {
+			t.Errorf("Score(%v) = %d, expected %d", test.statuses, score, test.score)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"html/template"
+	"io"
+)
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>batten report for {{.Host}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ccc; padding: 0.4em; text-align: left; vertical-align: top; }
+th { background: #eee; }
+td.passed { color: #2a7d2a; font-weight: bold; }
+td.failed, td.error { color: #c0392b; font-weight: bold; }
+td.not_applicable { color: #888; }
+pre { white-space: pre-wrap; margin: 0; }
+</style>
+</head>
+<body>
+<h1>batten report for {{.Host}}</h1>
+<p>Generated {{.Generated.Format "2006-01-02 15:04:05 MST"}} by batten {{.Version}}{{if .Scanner}} running {{.Scanner}}{{end}}.
+{{.Count "passed"}} passed, {{.Count "failed"}} failed, {{.Count "error"}} errors, {{.Count "not_applicable"}} not applicable.</p>
+<table>
+<tr><th>ID</th><th>Section</th><th>Name</th><th>Status</th><th>Details</th></tr>
+{{range .Results}}<tr>
+<td>{{.Identifier}}</td>
+<td>{{.Category}}</td>
+<td>{{.Name}}</td>
+<td class="{{.Status}}">{{.Status}}</td>
+<td>{{if eq .Status "error"}}<pre>{{.Error}}</pre>{{else if eq .Status "failed"}}<pre>{{.Description}}</pre>{{if .Findings}}<strong>Findings</strong><ul>{{range .Findings}}<li>{{.}}</li>{{end}}</ul>{{end}}<strong>Remediation</strong><pre>{{.Remediation}}</pre>{{end}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+func writeHTML(w io.Writer, report *Report) error {
+	return htmlReportTemplate.Execute(w, report)
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// writeJUnit renders the report as a single JUnit test suite named after
+// the host, with one test case per check.
+func writeJUnit(w io.Writer, report *Report) error {
+	suite := junitTestSuite{
+		Name:      "batten." + report.Host,
+		Tests:     len(report.Results),
+		Failures:  report.Count(StatusFailed),
+		Errors:    report.Count(StatusError),
+		Skipped:   report.Count(StatusNotApplicable),
+		Timestamp: report.Generated.Format("2006-01-02T15:04:05"),
+	}
+
+	for _, entry := range report.Results {
+		testCase := junitTestCase{
+			ClassName: entry.Category,
+			Name:      fmt.Sprintf("[%s] %s", entry.Identifier, entry.Name),
+		}
+		switch entry.Status {
+		case StatusFailed:
+			testCase.Failure = &junitMessage{
+				Message: entry.Description,
+				Body:    entry.Remediation,
+			}
+			for _, finding := range entry.Findings {
+				testCase.Failure.Body += "\n- " + finding.String()
+			}
+		case StatusError:
+			testCase.Error = &junitMessage{
+				Message: "There was an error executing the check",
+				Body:    entry.Error,
+			}
+		case StatusNotApplicable:
+			testCase.Skipped = &junitMessage{
+				Message: "Not applicable",
+			}
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dockersecuritytools/batten/batten"
+)
+
+const (
+	StatusPassed        = "passed"
+	StatusFailed        = "failed"
+	StatusError         = "error"
+	StatusNotApplicable = "not_applicable"
+)
+
+// Report is the serializable form of a scan. It carries enough of every
+// check definition to be rendered again without batten's check catalog,
+// so that a saved scan can be presented on a machine that has no Docker.
+type Report struct {
+	Host      string        `json:"host"`
+	Version   string        `json:"version"`
+	Scanner   string        `json:"scanner,omitempty"`
+	Generated time.Time     `json:"generated"`
+	Results   []ReportEntry `json:"results"`
+}
+
+// ReportEntry is the outcome of a single check within a `Report`.
+type ReportEntry struct {
+	Identifier  string          `json:"identifier"`
+	Category    string          `json:"category"`
+	Name        string          `json:"name"`
+	Level       int             `json:"level,omitempty"`
+	Severity    string          `json:"severity,omitempty"`
+	Description string          `json:"description"`
+	Rationale   string          `json:"rationale"`
+	Remediation string          `json:"remediation"`
+	References  []string        `json:"references,omitempty"`
+	Status      string          `json:"status"`
+	Error       string          `json:"error,omitempty"`
+	Findings    []ReportFinding `json:"findings,omitempty"`
+}
+
+// ReportFinding is one thing a check found wrong.
+type ReportFinding struct {
+	Subject string `json:"subject"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// String formats the finding for a line of text.
+func (f ReportFinding) String() string {
+	if f.Detail == "" {
+		return f.Subject
+	}
+	return f.Subject + ": " + f.Detail
+}
+
+// NewReportEntry converts the outcome of running a check into a report entry.
+func NewReportEntry(results *batten.CheckResults) ReportEntry {
+	checkdefinition := results.CheckDefinition
+
+	entry := ReportEntry{
+		Identifier:  checkdefinition.Identifier(),
+		Category:    checkdefinition.Category(),
+		Name:        checkdefinition.Name(),
+		Level:       checkdefinition.Level(),
+		Severity:    checkdefinition.Severity(),
+		Description: checkdefinition.Description(),
+		Rationale:   checkdefinition.Rationale(),
+		Remediation: checkdefinition.Remediation(),
+		References:  checkdefinition.References(),
+	}
+	for _, finding := range results.Findings {
+		entry.Findings = append(entry.Findings, ReportFinding{Subject: finding.Subject, Detail: finding.Detail})
+	}
+
+	if results.NotApplicable {
+		entry.Status = StatusNotApplicable
+	} else if results.Error != nil {
+		entry.Status = StatusError
+		entry.Error = results.Error.Error()
+	} else if results.Success {
+		entry.Status = StatusPassed
+	} else {
+		entry.Status = StatusFailed
+	}
+	return entry
+}
+
+// NewReport builds a report for `host` out of check results.
+func NewReport(host string, version string, results []*batten.CheckResults) *Report {
+	report := &Report{
+		Host:      host,
+		Version:   version,
+		Generated: time.Now().UTC(),
+		Results:   make([]ReportEntry, 0, len(results)),
+	}
+	for _, r := range results {
+		report.Results = append(report.Results, NewReportEntry(r))
+	}
+	return report
+}
+
+// ReadReport decodes a report previously written in the `json` format.
+func ReadReport(r io.Reader) (*Report, error) {
+	report := &Report{}
+	if err := json.NewDecoder(r).Decode(report); err != nil {
+		return nil, fmt.Errorf("could not decode report: %v", err)
+	}
+	return report, nil
+}
+
+// Filter drops the entries of checks that `filter` does not select.
+func (r *Report) Filter(filter *batten.CheckFilter) {
+	results := make([]ReportEntry, 0, len(r.Results))
+	for _, entry := range r.Results {
+		if filter.Matches(entry.Identifier, entry.Category, entry.Level, entry.Severity) {
+			results = append(results, entry)
+		}
+	}
+	r.Results = results
+}
+
+// Count returns the number of entries with the given status.
+func (r *Report) Count(status string) int {
+	var count int
+	for _, entry := range r.Results {
+		if entry.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Formats lists the output formats a report can be rendered into.
+var Formats = []string{"console", "json", "html", "junit", "sarif"}
+
+// WriteReport renders `report` to `w` in the given format.
+func WriteReport(w io.Writer, format string, report *Report) error {
+	switch format {
+	case "console":
+		FormatReportForConsole(w, report)
+		return nil
+	case "json":
+		return writeJSON(w, report)
+	case "html":
+		return writeHTML(w, report)
+	case "junit":
+		return writeJUnit(w, report)
+	case "sarif":
+		return writeSARIF(w, report)
+	}
+	return fmt.Errorf("unknown report format '%s'", format)
+}
+
+func writeJSON(w io.Writer, report interface{}) error {
+	bytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", bytes)
+	return err
+}
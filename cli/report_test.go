@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testReport() *Report {
+	return &Report{
+		Host:      "web-01",
+		Version:   "0.1.0",
+		Scanner:   "jerbi/batten@sha256:0123 (sha256:4567)",
+		Generated: time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+		Results: []ReportEntry{
+			{Identifier: "CIS-Docker-Benchmark-2.1", Category: "Docker daemon configuration", Name: "Do not use lxc execution driver", Level: 1, Severity: "low", Status: StatusPassed},
+			{
+				Identifier: "CIS-Docker-Benchmark-5.4", Category: "Container Runtime", Name: "Restrict Linux Kernel Capabilities within containers",
+				Level: 1, Severity: "high", Description: "Capabilities & <privileges>", Remediation: `Run with "--cap-drop=ALL"`,
+				References: []string{"https://docs.docker.com/engine/reference/run/"},
+				Status:     StatusFailed,
+				Findings: []ReportFinding{
+					{Subject: "web (nginx)", Detail: "has capabilities the policy does not allow: NET_ADMIN"},
+					{Subject: "<script>alert(1)</script>"},
+				},
+			},
+			{Identifier: "CIS-Docker-Benchmark-1.8", Category: "Host Configuration", Name: "Audit docker daemon", Status: StatusError, Error: "exec: \"auditctl\": not found"},
+			{Identifier: "CIS-Docker-Benchmark-1.1", Category: "Host Configuration", Name: "Create a separate partition for containers", Status: StatusNotApplicable},
+		},
+	}
+}
+
+func TestJSONReportRoundTrip(t *testing.T) {
+	report := testReport()
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, "json", report); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadReport(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !read.Generated.Equal(report.Generated) {
+		t.Errorf("generated %v, expected %v", read.Generated, report.Generated)
+	}
+	read.Generated = report.Generated
+	if !reflect.DeepEqual(read, report) {
+		t.Errorf("got %+v, expected %+v", read, report)
+	}
+}
+
+func TestJUnitReport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, "junit", testReport()); err != nil {
+		t.Fatal(err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, buf.String())
+	}
+	if len(suites.Suites) != 1 {
+		t.Fatalf("expected one test suite, got %d", len(suites.Suites))
+	}
+	suite := suites.Suites[0]
+	if suite.Tests != 4 || suite.Failures != 1 || suite.Errors != 1 || suite.Skipped != 1 || len(suite.TestCases) != 4 {
+		t.Errorf("unexpected suite %+v", suite)
+	}
+	failure := suite.TestCases[1].Failure
+	if failure == nil || !strings.Contains(failure.Body, "<script>alert(1)</script>") {
+		t.Errorf("expected the findings in the failure, got %+v", failure)
+	}
+}
+
+func TestSARIFReport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, "sarif", testReport()); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if log.Version != sarifVersion || len(log.Runs) != 1 {
+		t.Fatalf("unexpected log %+v", log)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 4 {
+		t.Errorf("expected a rule per check, got %d", len(run.Tool.Driver.Rules))
+	}
+	var kinds []string
+	for _, result := range run.Results {
+		kinds = append(kinds, result.Kind)
+	}
+	// the failed check has a result per finding
+	if expected := []string{"pass", "fail", "fail", "open", "notApplicable"}; !reflect.DeepEqual(kinds, expected) {
+		t.Errorf("got results %v, expected %v", kinds, expected)
+	}
+}
+
+func TestHTMLReport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, "html", testReport()); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "<script>") {
+		t.Errorf("findings are not escaped:\n%s", out)
+	}
+
+	// every element is closed, allowing for the void elements of HTML
+	decoder := xml.NewDecoder(strings.NewReader(out))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+	var open []string
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid HTML: %v\n%s", err, out)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			open = append(open, token.Name.Local)
+		case xml.EndElement:
+			if len(open) == 0 || open[len(open)-1] != token.Name.Local {
+				t.Fatalf("unexpected </%s> with %v open", token.Name.Local, open)
+			}
+			open = open[:len(open)-1]
+		}
+	}
+	if len(open) > 0 {
+		t.Errorf("elements left open: %v", open)
+	}
+}
+
+func TestPlainWriter(t *testing.T) {
+	var buf bytes.Buffer
+	FormatReportForConsole(NewPlainWriter(&buf), testReport())
+	out := buf.String()
+	if strings.Contains(out, "\x1b") {
+		t.Errorf("escape sequences left in %q", out)
+	}
+	if !strings.Contains(out, "[2/4] FAILED [CIS-Docker-Benchmark-5.4]") {
+		t.Errorf("missing status line in %q", out)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(b []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestWriteReportErrors(t *testing.T) {
+	for _, format := range []string{"json", "junit", "sarif", "html"} {
+		if err := WriteReport(failingWriter{}, format, testReport()); err == nil {
+			t.Errorf("%s: expected the write error", format)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	FullDescription  sarifMessage `json:"fullDescription"`
+	Help             sarifMessage `json:"help"`
+	HelpURI          string       `json:"helpUri,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID  string       `json:"ruleId"`
+	Kind    string       `json:"kind"`
+	Level   string       `json:"level"`
+	Message sarifMessage `json:"message"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+// writeSARIF renders the report as a SARIF 2.1.0 log with one rule per
+// check. Passed checks are kept as `pass` results so that the log records
+// everything that was evaluated, not just the findings.
+func writeSARIF(w io.Writer, report *Report) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "batten",
+				Version:        report.Version,
+				InformationURI: "http://dockersecurity.org",
+			},
+		},
+		Results: []sarifResult{},
+	}
+
+	for _, entry := range report.Results {
+		rule := sarifRule{
+			ID:               entry.Identifier,
+			Name:             entry.Name,
+			ShortDescription: sarifMessage{Text: entry.Name},
+			FullDescription:  sarifMessage{Text: entry.Description},
+			Help:             sarifMessage{Text: entry.Remediation},
+		}
+		if len(entry.References) > 0 {
+			rule.HelpURI = entry.References[0]
+		}
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+
+		result := sarifResult{RuleID: entry.Identifier}
+		switch entry.Status {
+		case StatusPassed:
+			result.Kind = "pass"
+			result.Level = "none"
+			result.Message.Text = fmt.Sprintf("%s: passed on %s", entry.Name, report.Host)
+		case StatusNotApplicable:
+			result.Kind = "notApplicable"
+			result.Level = "none"
+			result.Message.Text = fmt.Sprintf("%s: not applicable to %s", entry.Name, report.Host)
+		case StatusError:
+			result.Kind = "open"
+			result.Level = "none"
+			result.Message.Text = fmt.Sprintf("There was an error executing the check on %s: %s", report.Host, entry.Error)
+		default:
+			result.Kind = "fail"
+			result.Level = "error"
+			result.Message.Text = fmt.Sprintf("%s: failed on %s", entry.Name, report.Host)
+
+			// report every finding as a result of its own
+			for _, finding := range entry.Findings {
+				found := result
+				found.Message.Text = fmt.Sprintf("%s: %s on %s", entry.Name, finding, report.Host)
+				run.Results = append(run.Results, found)
+			}
+			if len(entry.Findings) > 0 {
+				continue
+			}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	bytes, err := json.MarshalIndent(sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{run},
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", bytes)
+	return err
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/dockersecuritytools/batten/batten"
+	"github.com/dockersecuritytools/batten/cli"
+	"github.com/mgutz/ansi"
+	"gopkg.in/yaml.v2"
+)
+
+// inventory is the list of hosts `batten fleet` scans, read from YAML:
+//
+//	hosts:
+//	  - name: web-01
+//	    host: tcp://10.0.0.1:2376
+//	    tlscacert: certs/ca.pem
+//	    tlscert: certs/cert.pem
+//	    tlskey: certs/key.pem
+//	    tlsskipverify: false
+//	    labels:
+//	      env: production
+type inventory struct {
+	Hosts []inventoryHost `yaml:"hosts"`
+}
+
+type inventoryHost struct {
+	Name          string            `yaml:"name"`
+	Host          string            `yaml:"host"`
+	TLSCACert     string            `yaml:"tlscacert"`
+	TLSCert       string            `yaml:"tlscert"`
+	TLSKey        string            `yaml:"tlskey"`
+	TLSSkipVerify bool              `yaml:"tlsskipverify"`
+	Labels        map[string]string `yaml:"labels"`
+}
+
+// readInventory reads and validates an inventory file. Hosts without a
+// name are named after their URL, and relative certificate paths are
+// relative to the inventory file.
+func readInventory(filename string) (*inventory, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	inv := &inventory{}
+	if err := yaml.UnmarshalStrict(data, inv); err != nil {
+		return nil, err
+	}
+	if len(inv.Hosts) == 0 {
+		return nil, fmt.Errorf("no hosts in inventory")
+	}
+
+	dir := filepath.Dir(filename)
+	names := make(map[string]bool)
+	for i := range inv.Hosts {
+		h := &inv.Hosts[i]
+		if h.Host == "" {
+			return nil, fmt.Errorf("host #%d has no 'host' URL", i+1)
+		}
+		if h.Name == "" {
+			h.Name = h.Host
+		}
+		if names[h.Name] {
+			return nil, fmt.Errorf("host '%s' is listed more than once", h.Name)
+		}
+		names[h.Name] = true
+
+		for _, p := range []*string{&h.TLSCACert, &h.TLSCert, &h.TLSKey} {
+			if *p != "" && !filepath.IsAbs(*p) {
+				*p = filepath.Join(dir, *p)
+			}
+		}
+	}
+	return inv, nil
+}
+
+// a host that does not answer a ping within this long is unreachable
+const fleetPingTimeout = 30 * time.Second
+
+// fleetScanner runs remote scans on many hosts at once, and keeps track
+// of the scans in progress so that they can all be cleaned up when
+// batten is interrupted.
+type fleetScanner struct {
+	timeout time.Duration
+
+	mu     sync.Mutex
+	active map[*remoteScan]bool
+}
+
+// scanHost scans a single host, giving up after the scanner's timeout.
+// No request to the host takes longer than that either, so a scan that
+// was given up on finishes soon after without leaving anything behind.
+func (f *fleetScanner) scanHost(h inventoryHost) cli.FleetHost {
+	result := cli.FleetHost{Name: h.Name, Host: h.Host, Labels: h.Labels}
+
+	endpoint := batten.Endpoint{
+		Host:          h.Host,
+		TLSCACert:     h.TLSCACert,
+		TLSCert:       h.TLSCert,
+		TLSKey:        h.TLSKey,
+		TLSSkipVerify: h.TLSSkipVerify,
+	}
+	endpoint.Timeout = fleetPingTimeout
+	if f.timeout < endpoint.Timeout {
+		endpoint.Timeout = f.timeout
+	}
+	client, err := endpoint.NewClient()
+	if err == nil {
+		err = client.Ping()
+	}
+	if err != nil {
+		result.Status, result.Error = cli.FleetUnreachable, err.Error()
+		return result
+	}
+
+	endpoint.Timeout = f.timeout
+	client, err = endpoint.NewClient()
+	if err != nil {
+		result.Status, result.Error = cli.FleetError, err.Error()
+		return result
+	}
+	scan := &remoteScan{client: client, host: h.Name, progress: ioutil.Discard}
+
+	type outcome struct {
+		report *cli.Report
+		err    error
+	}
+	done := make(chan outcome, 1)
+	f.track(scan, true)
+	go func() {
+		report, err := scan.run(*scanImage, *scanPull, *scanImageArchive)
+		scan.cleanUp()
+		f.track(scan, false)
+		done <- outcome{report: report, err: err}
+	}()
+
+	select {
+	case o := <-done:
+		if o.err != nil {
+			result.Status, result.Error = cli.FleetError, o.err.Error()
+			return result
+		}
+		o.report.Host = h.Host
+		result.Status, result.Report, result.Score = cli.FleetScanned, o.report, cli.Score(o.report)
+	case <-time.After(f.timeout):
+		// don't leave a hung scan running on the host, and wait for it to
+		// give up rather than leave it running here
+		scan.interrupt()
+		<-done
+		result.Status, result.Error = cli.FleetError, fmt.Sprintf("timed out after %v", f.timeout)
+	}
+	return result
+}
+
+func (f *fleetScanner) track(scan *remoteScan, active bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if active {
+		f.active[scan] = true
+	} else {
+		delete(f.active, scan)
+	}
+}
+
+// interrupt stops every scan in progress.
+func (f *fleetScanner) interrupt() {
+	f.mu.Lock()
+	var wg sync.WaitGroup
+	for scan := range f.active {
+		wg.Add(1)
+		go func(scan *remoteScan) {
+			defer wg.Done()
+			scan.interrupt()
+		}(scan)
+	}
+	f.mu.Unlock()
+	wg.Wait()
+}
+
+// fleetCheck scans every host in the inventory with at most `workers`
+// scans running at a time, and writes the aggregate report.
+func fleetCheck(filename string, workers int, timeout time.Duration, format string, output string) {
+	inv, err := readInventory(filename)
+	if err != nil {
+		fatalf("Failed to read inventory '%s'. Error: %v", filename, err)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	f := &fleetScanner{timeout: timeout, active: make(map[*remoteScan]bool)}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		colorPrint(ansi.Yellow, "Received %v, stopping the scans in progress...", sig)
+		f.interrupt()
+		os.Exit(1)
+	}()
+
+	report := &cli.FleetReport{
+		Version:   Version,
+		Generated: time.Now().UTC(),
+		Hosts:     make([]cli.FleetHost, len(inv.Hosts)),
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				result := f.scanHost(inv.Hosts[i])
+				report.Hosts[i] = result
+				if result.Status == cli.FleetScanned {
+					colorPrint(ansi.Green, "Scanned host '%s': score %d%%.", result.Name, result.Score)
+				} else {
+					colorPrint(ansi.Red, "Failed to scan host '%s' (%s). Error: %s", result.Name, result.Status, result.Error)
+				}
+			}
+		}()
+	}
+	for i := range inv.Hosts {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	signal.Stop(signals)
+
+	w := os.Stdout
+	if output != "" {
+		if w, err = os.Create(output); err != nil {
+			fatalf("Failed to create '%s'. Error: %v", output, err)
+		}
+	}
+	var out io.Writer = w
+	if format == "console" && !isTerminal(w) {
+		out = cli.NewPlainWriter(w)
+	}
+	if err := cli.WriteFleetReport(out, format, report); err != nil {
+		fatalf("Failed to write %s fleet report. Error: %v", format, err)
+	}
+	if output != "" {
+		if err := w.Close(); err != nil {
+			fatalf("Failed to write '%s'. Error: %v", output, err)
+		}
+	}
+}
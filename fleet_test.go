@@ -0,0 +1,109 @@
+package main
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dockersecuritytools/batten/cli"
+)
+
+func TestReadInventory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "batten-inventory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		inventory string
+		expected  []inventoryHost
+		err       string
+	}{
+		{
+			`
+hosts:
+  - name: web-01
+    host: tcp://10.0.0.1:2376
+    tlscacert: certs/ca.pem
+    tlscert: /etc/batten/cert.pem
+    tlskey: certs/key.pem
+    labels:
+      env: production
+  - host: tcp://10.0.0.2:2375
+`,
+			[]inventoryHost{
+				{
+					Name: "web-01", Host: "tcp://10.0.0.1:2376",
+					TLSCACert: filepath.Join(dir, "certs/ca.pem"), TLSCert: "/etc/batten/cert.pem", TLSKey: filepath.Join(dir, "certs/key.pem"),
+					Labels: map[string]string{"env": "production"},
+				},
+				{Name: "tcp://10.0.0.2:2375", Host: "tcp://10.0.0.2:2375"},
+			},
+			"",
+		},
+		{"hosts: []", nil, "no hosts in inventory"},
+		{"hosts:\n  - name: web-01\n", nil, "host #1 has no 'host' URL"},
+		{"hosts:\n  - host: tcp://10.0.0.1:2376\n  - host: tcp://10.0.0.1:2376\n", nil, "host 'tcp://10.0.0.1:2376' is listed more than once"},
+		{"hosts:\n  - host: tcp://10.0.0.1:2376\n    tlsca: ca.pem\n", nil, "field tlsca not found"},
+	}
+	for _, test := range tests {
+		filename := filepath.Join(dir, "hosts.yaml")
+		if err := ioutil.WriteFile(filename, []byte(test.inventory), 0600); err != nil {
+			t.Fatal(err)
+		}
+		inv, err := readInventory(filename)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: expected error %q, got %v", test.inventory, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.inventory, err)
+		} else if !reflect.DeepEqual(inv.Hosts, test.expected) {
+			t.Errorf("%s: got %+v, expected %+v", test.inventory, inv.Hosts, test.expected)
+		}
+	}
+
+	if _, err := readInventory(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing inventory")
+	}
+}
+
+// A host that accepts connections but never answers is unreachable, and
+// is given up on within the timeout.
+func TestScanHostHung(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	f := &fleetScanner{timeout: 200 * time.Millisecond, active: make(map[*remoteScan]bool)}
+	start := time.Now()
+	result := f.scanHost(inventoryHost{Name: "hung", Host: "tcp://" + listener.Addr().String()})
+	if result.Status != cli.FleetUnreachable {
+		t.Errorf("expected the host to be unreachable, got %+v", result)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("gave up on the host after %v", elapsed)
+	}
+	if len(f.active) > 0 {
+		t.Errorf("scans left active: %v", f.active)
+	}
+}
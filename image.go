@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/mgutz/ansi"
+)
+
+const (
+	PullAlways  = "always"
+	PullMissing = "missing"
+	PullNever   = "never"
+
+	// the index Docker Hub credentials are stored under
+	DockerHubAuthIndex = "https://index.docker.io/v1/"
+)
+
+// splitImageReference splits an image reference such as
+// `registry:5000/batten:0.1.0` or `jerbi/batten@sha256:...` into the
+// repository and the tag or digest to pull. An untagged reference means
+// the `latest` tag, as it does for `docker pull`.
+func splitImageReference(ref string) (repository string, tag string) {
+	if i := strings.Index(ref, "@"); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	// a colon after the last slash separates the tag; before it, it is
+	// the port of the registry
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, "latest"
+}
+
+// registryOf returns the registry a repository is pulled from, in the
+// form it is stored under in the docker CLI configuration.
+func registryOf(repository string) string {
+	parts := strings.SplitN(repository, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		return parts[0]
+	}
+	return DockerHubAuthIndex
+}
+
+// registryAuth reads the credentials for `registry` that `docker login`
+// stored in ~/.docker/config.json, or in ~/.dockercfg for older clients.
+// Registries without stored credentials are pulled from anonymously.
+// Credential helpers (`credsStore`) are not supported.
+func registryAuth(registry string) (docker.AuthConfiguration, error) {
+	var auths map[string]struct {
+		Auth  string `json:"auth"`
+		Email string `json:"email"`
+	}
+
+	home := os.Getenv("HOME")
+	if f, err := os.Open(path.Join(home, ".docker", "config.json")); err == nil {
+		defer f.Close()
+		var config struct {
+			Auths json.RawMessage `json:"auths"`
+		}
+		if err := json.NewDecoder(f).Decode(&config); err != nil {
+			return docker.AuthConfiguration{}, fmt.Errorf("could not read %s: %v", f.Name(), err)
+		}
+		if len(config.Auths) > 0 {
+			if err := json.Unmarshal(config.Auths, &auths); err != nil {
+				return docker.AuthConfiguration{}, fmt.Errorf("could not read %s: %v", f.Name(), err)
+			}
+		}
+	} else if f, err := os.Open(path.Join(home, ".dockercfg")); err == nil {
+		defer f.Close()
+		if err := json.NewDecoder(f).Decode(&auths); err != nil {
+			return docker.AuthConfiguration{}, fmt.Errorf("could not read %s: %v", f.Name(), err)
+		}
+	}
+
+	for server, entry := range auths {
+		if server != registry && strings.TrimPrefix(strings.TrimPrefix(server, "https://"), "http://") != registry {
+			continue
+		}
+		if entry.Auth == "" {
+			break
+		}
+		data, err := base64.StdEncoding.DecodeString(entry.Auth)
+		if err != nil {
+			return docker.AuthConfiguration{}, fmt.Errorf("invalid credentials for %s: %v", server, err)
+		}
+		userpass := strings.SplitN(string(data), ":", 2)
+		if len(userpass) != 2 {
+			return docker.AuthConfiguration{}, fmt.Errorf("invalid credentials for %s", server)
+		}
+		return docker.AuthConfiguration{
+			Username:      userpass[0],
+			Password:      userpass[1],
+			Email:         entry.Email,
+			ServerAddress: server,
+		}, nil
+	}
+	return docker.AuthConfiguration{}, nil
+}
+
+// prepareImage makes sure the scanner image is present on the host,
+// loading it from a `docker save` archive or pulling it as the pull policy
+// says. It returns the image that will run, and records whether it was not
+// on the host before. An image that was not is recorded by its reference
+// before it is pulled or loaded, so that an interrupt removes it too.
+func (s *remoteScan) prepareImage(ref string, pull string, archive string) (*docker.Image, error) {
+	existing, err := s.client.InspectImage(ref)
+	if err != nil && err != docker.ErrNoSuchImage {
+		return nil, err
+	}
+	if archive == "" && pull != PullAlways && (pull != PullMissing || existing != nil) {
+		if existing == nil {
+			return nil, fmt.Errorf("image '%s' is not present and pulling is disabled", ref)
+		}
+		s.mu.Lock()
+		s.image = existing
+		s.mu.Unlock()
+		return existing, nil
+	}
+
+	s.mu.Lock()
+	if s.interrupted {
+		s.mu.Unlock()
+		return nil, errScanInterrupted
+	}
+	if existing == nil {
+		s.fetching = ref
+	}
+	s.mu.Unlock()
+
+	if archive != "" {
+		f, err := os.Open(archive)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		colorPrint(ansi.Green, "Loading image '%s' from '%s' on host '%s'...", ref, archive, s.host)
+		if err := s.client.LoadImage(docker.LoadImageOptions{InputStream: f}); err != nil {
+			return nil, fmt.Errorf("failed to load '%s': %v", archive, err)
+		}
+	} else {
+		repository, tag := splitImageReference(ref)
+		auth, err := registryAuth(registryOf(repository))
+		if err != nil {
+			return nil, err
+		}
+
+		colorPrint(ansi.Green, "Pulling image '%s' on host '%s'...", ref, s.host)
+		opts := docker.PullImageOptions{Repository: repository, Tag: tag, OutputStream: ioutil.Discard}
+		if err := s.client.PullImage(opts, auth); err != nil {
+			return nil, fmt.Errorf("failed to pull '%s': %v", ref, err)
+		}
+	}
+
+	image, err := s.client.InspectImage(ref)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.image, s.createdImage, s.fetching = image, existing == nil, ""
+	s.mu.Unlock()
+	return image, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func TestSplitImageReference(t *testing.T) {
+	tests := []struct {
+		ref        string
+		repository string
+		tag        string
+	}{
+		{"batten", "batten", "latest"},
+		{"jerbi/batten", "jerbi/batten", "latest"},
+		{"jerbi/batten:0.1.0", "jerbi/batten", "0.1.0"},
+		{"registry:5000/batten", "registry:5000/batten", "latest"},
+		{"registry:5000/batten:0.1.0", "registry:5000/batten", "0.1.0"},
+		{"registry.example.com:5000/security/batten:0.1.0", "registry.example.com:5000/security/batten", "0.1.0"},
+		{"jerbi/batten@sha256:0123abcd", "jerbi/batten", "sha256:0123abcd"},
+		{"registry:5000/batten@sha256:0123abcd", "registry:5000/batten", "sha256:0123abcd"},
+	}
+	for _, test := range tests {
+		repository, tag := splitImageReference(test.ref)
+		if repository != test.repository || tag != test.tag {
+			t.Errorf("%s: got %q %q, expected %q %q", test.ref, repository, tag, test.repository, test.tag)
+		}
+	}
+}
+
+func TestRegistryOf(t *testing.T) {
+	tests := []struct {
+		repository string
+		registry   string
+	}{
+		{"batten", DockerHubAuthIndex},
+		{"jerbi/batten", DockerHubAuthIndex},
+		{"jerbi/tools/batten", DockerHubAuthIndex},
+		{"localhost/batten", "localhost"},
+		{"localhost:5000/batten", "localhost:5000"},
+		{"registry:5000/batten", "registry:5000"},
+		{"registry.example.com/security/batten", "registry.example.com"},
+	}
+	for _, test := range tests {
+		if registry := registryOf(test.repository); registry != test.registry {
+			t.Errorf("%s: got %q, expected %q", test.repository, registry, test.registry)
+		}
+	}
+}
+
+func TestRegistryAuth(t *testing.T) {
+	auth := func(userpass string) string {
+		return base64.StdEncoding.EncodeToString([]byte(userpass))
+	}
+	tests := []struct {
+		file     string
+		config   string
+		registry string
+		expected docker.AuthConfiguration
+		err      bool
+	}{
+		{
+			".docker/config.json",
+			`{"auths": {"https://index.docker.io/v1/": {"auth": "` + auth("jerbi:secret") + `", "email": "jerbi@example.com"}}}`,
+			DockerHubAuthIndex,
+			docker.AuthConfiguration{Username: "jerbi", Password: "secret", Email: "jerbi@example.com", ServerAddress: DockerHubAuthIndex},
+			false,
+		},
+		{
+			".docker/config.json",
+			`{"auths": {"https://registry:5000": {"auth": "` + auth("scanner:pass:word") + `"}}}`,
+			"registry:5000",
+			docker.AuthConfiguration{Username: "scanner", Password: "pass:word", ServerAddress: "https://registry:5000"},
+			false,
+		},
+		{
+			".docker/config.json",
+			`{"auths": {"registry:5000": {"auth": "` + auth("scanner:secret") + `"}}}`,
+			DockerHubAuthIndex,
+			docker.AuthConfiguration{},
+			false,
+		},
+		{
+			".docker/config.json",
+			`{"credsStore": "desktop"}`,
+			"registry:5000",
+			docker.AuthConfiguration{},
+			false,
+		},
+		{
+			".dockercfg",
+			`{"registry:5000": {"auth": "` + auth("scanner:secret") + `"}}`,
+			"registry:5000",
+			docker.AuthConfiguration{Username: "scanner", Password: "secret", ServerAddress: "registry:5000"},
+			false,
+		},
+		{".docker/config.json", `{"auths": {"registry:5000": {"auth": "not base64"}}}`, "registry:5000", docker.AuthConfiguration{}, true},
+		{".docker/config.json", `{"auths": {"registry:5000": {"auth": "` + auth("scanner") + `"}}}`, "registry:5000", docker.AuthConfiguration{}, true},
+		{".docker/config.json", `{"auths": [}`, "registry:5000", docker.AuthConfiguration{}, true},
+	}
+
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	for _, test := range tests {
+		home, err := ioutil.TempDir("", "batten-home")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(home)
+		if err := os.MkdirAll(path.Join(home, path.Dir(test.file)), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path.Join(home, test.file), []byte(test.config), 0600); err != nil {
+			t.Fatal(err)
+		}
+		os.Setenv("HOME", home)
+
+		got, err := registryAuth(test.registry)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s for %s: expected an error, got %+v", test.config, test.registry, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s for %s: %v", test.config, test.registry, err)
+		} else if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s for %s: got %+v, expected %+v", test.config, test.registry, got, test.expected)
+		}
+	}
+}
@@ -2,93 +2,320 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
-	"github.com/mgutz/ansi"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/dockersecuritytools/batten/batten"
+	"github.com/dockersecuritytools/batten/cli"
 	docker "github.com/fsouza/go-dockerclient"
+	"github.com/mgutz/ansi"
 )
 
 const (
-	BattenDockerRepository = "jerbi/batten"
+	BattenDockerRepository = "jerbi/batten:latest"
+	BattenBinaryPath       = "/go/src/github.com/dockersecuritytools/batten/bin/batten"
+
+	// where the scan container finds the host's root filesystem
+	ScanHostRoot = "/host"
+
+	// scan containers are named with this prefix and carry this label, so
+	// that leftovers can be told apart from the host's own containers
+	ScanContainerPrefix = "batten-scan-"
+	ScanContainerLabel  = "org.dockersecurity.batten.scan"
+
+	// seconds a scan container is given to stop before it is killed
+	scanStopTimeout = 5
 )
 
+var errScanInterrupted = errors.New("the scan was interrupted")
 
+// colorPrint writes a status message to stderr, keeping stdout free for
+// the results.
 func colorPrint(color string, format string, args ...interface{}) {
-	fmt.Printf(color + format + ansi.Reset + "\n", args...)
+	fmt.Fprintf(os.Stderr, color+format+ansi.Reset+"\n", args...)
 }
 
+// newDockerClient connects to the host given with --server, with the TLS
+// material given on the command line or in the Docker environment
+// variables.
 func newDockerClient() (*docker.Client, error) {
-	if len(*tlscert) == 0 {
-		return docker.NewClient(*serverIP)
-	} else {
-		return docker.NewTLSClient(*serverIP, *tlscert, *tlskey, *tlscacert)
+	endpoint, err := newDockerEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	return endpoint.NewClient()
+}
+
+// newDockerEndpoint is the remote daemon given with --server, with the
+// TLS material of the command line if there is any.
+func newDockerEndpoint() (*batten.Endpoint, error) {
+	endpoint, err := batten.ResolveEndpoint(*serverIP, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(*tlscert) > 0 || len(*tlscacert) > 0 {
+		endpoint.TLSCACert, endpoint.TLSCert, endpoint.TLSKey = *tlscacert, *tlscert, *tlskey
+	}
+	if *tlsSkipVerify {
+		endpoint.TLSSkipVerify = true
 	}
+	return endpoint, nil
 }
 
-func cleanUp(client *docker.Client, container *docker.Container) {
-	client.RemoveContainer(docker.RemoveContainerOptions{ID: container.ID})
-	client.RemoveImage(BattenDockerRepository)
+// remoteScan is a scan run in a container on a remote host. It remembers
+// which resources it created there, so that cleaning up removes those and
+// nothing the host had before.
+type remoteScan struct {
+	client *docker.Client
+	host   string
+
+	// where the scan's progress is streamed to
+	progress io.Writer
+
+	mu           sync.Mutex
+	interrupted  bool
+	image        *docker.Image
+	createdImage bool
+	container    *docker.Container
+
+	// the reference of a scanner image that was not on the host, while it
+	// is pulled or loaded
+	fetching string
 }
 
-func remoteCheck() (error){	
-	client, err := newDockerClient()
-	
+// cleanUp removes the scan container and, if the scan pulled or loaded it,
+// the scanner image, even while it is being pulled or loaded. It is safe to call more than once and from a signal
+// handler while the scan is running.
+func (s *remoteScan) cleanUp() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil {
+		err := s.client.RemoveContainer(docker.RemoveContainerOptions{ID: s.container.ID, RemoveVolumes: true, Force: true})
+		if err != nil {
+			colorPrint(ansi.Yellow, "Failed to remove container '%s' on host '%s'. Error: %v", s.container.ID, s.host, err)
+		}
+		s.container = nil
+	}
+	if s.createdImage {
+		if err := s.client.RemoveImage(s.image.ID); err != nil {
+			colorPrint(ansi.Yellow, "Failed to remove image '%s' on host '%s'. Error: %v", s.image.ID, s.host, err)
+		}
+		s.createdImage = false
+	}
+	if s.fetching != "" {
+		// the image may not have been pulled or loaded yet
+		if err := s.client.RemoveImage(s.fetching); err != nil && err != docker.ErrNoSuchImage {
+			colorPrint(ansi.Yellow, "Failed to remove image '%s' on host '%s'. Error: %v", s.fetching, s.host, err)
+		}
+		s.fetching = ""
+	}
+}
+
+// interrupt stops a running scan and cleans up after it. A scan that has
+// not pulled or loaded its image or created its container yet will not.
+func (s *remoteScan) interrupt() {
+	s.mu.Lock()
+	s.interrupted = true
+	if s.container != nil {
+		s.client.StopContainer(s.container.ID, scanStopTimeout)
+	}
+	s.mu.Unlock()
+	s.cleanUp()
+}
+
+// run prepares the scanner image, runs the scan container to completion
+// and returns the report it produced. Whatever it created is left for
+// `cleanUp`.
+func (s *remoteScan) run(ref string, pull string, archive string) (*cli.Report, error) {
+	image, err := s.prepareImage(ref, pull, archive)
+	if err == errScanInterrupted {
+		return nil, err
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to prepare image '%s': %v", ref, err)
+	}
+
+	// Create Batten container. It audits the host through a read-only
+	// mount of its root filesystem, sees the host's processes, and may
+	// read the kernel audit rules and the daemon's environment.
+	config := &docker.Config{
+		Image:  image.ID,
+		Cmd:    []string{BattenBinaryPath, "check", "--host-root=" + ScanHostRoot, "--format=json"},
+		Labels: map[string]string{ScanContainerLabel: Version},
+	}
+	hostConfig := &docker.HostConfig{
+		Binds: []string{
+			"/var/run/docker.sock:/var/run/docker.sock",
+			"/:" + ScanHostRoot + ":ro",
+		},
+		PidMode: "host",
+		CapAdd:  []string{"AUDIT_CONTROL", "SYS_PTRACE"},
+	}
+	name := fmt.Sprintf("%s%d", ScanContainerPrefix, time.Now().UnixNano())
+
+	// The container is created with the lock held, so that an interrupt
+	// either comes before it and nothing is created, or finds the
+	// container to remove.
+	s.mu.Lock()
+	if s.interrupted {
+		s.mu.Unlock()
+		return nil, errScanInterrupted
+	}
+	container, err := s.client.CreateContainer(docker.CreateContainerOptions{Name: name, Config: config, HostConfig: hostConfig})
+	if err == nil {
+		s.container = container
+	}
+	s.mu.Unlock()
 	if err != nil {
-		fatalf("Failed to connect to host '%s'. Error: %v", *serverIP,err)
+		return nil, fmt.Errorf("failed to create container: %v", err)
 	}
-	
-	// Pull Batten image from the Docker repository
-	var pullBuf bytes.Buffer
-	colorPrint(ansi.Green, "Pulling image '%s' on host '%s'...", BattenDockerRepository, *serverIP)
-	err = client.PullImage(docker.PullImageOptions{Repository: BattenDockerRepository, OutputStream: &pullBuf}, docker.AuthConfiguration{})
+
+	// Start Batten container; its host config was given on creation
+	if err := s.client.StartContainer(container.ID, nil); err != nil {
+		return nil, fmt.Errorf("failed to start container: %v", err)
+	}
+
+	// Stream the scan's progress from the container's stderr while it
+	// runs. Its stdout carries the results as JSON once it is done.
+	colorPrint(ansi.Green, "Running scan on host '%s'...", s.host)
+	var resultsBuf bytes.Buffer
+	attached := make(chan error, 1)
+	go func() {
+		attached <- s.client.AttachToContainer(docker.AttachToContainerOptions{
+			Container:    container.ID,
+			OutputStream: &resultsBuf,
+			ErrorStream:  s.progress,
+			Stdout:       true,
+			Stderr:       true,
+			Logs:         true,
+			Stream:       true,
+		})
+	}()
+
+	code, err := s.client.WaitContainer(container.ID)
 	if err != nil {
-		fatalf("Failed to pull '%s' image on host '%s'. Error: %v", BattenDockerRepository, *serverIP, err)
+		return nil, fmt.Errorf("failed to wait for the scan: %v", err)
 	}
-	
-	// Create Batten container
-	config := &docker.Config{Image: BattenDockerRepository}
-	container, err := client.CreateContainer(docker.CreateContainerOptions{Config: config})
+	if err := <-attached; err != nil {
+		return nil, fmt.Errorf("failed to attach to container output: %v", err)
+	}
+
+	// A scan that ran to completion always leaves a report behind, whether
+	// or not its checks passed. Anything else means the scan itself broke.
+	report, err := cli.ReadReport(&resultsBuf)
+	if code != 0 || err != nil {
+		return nil, fmt.Errorf("scan crashed. Exit code: %d, error: %v", code, err)
+	}
+	report.Host = s.host
+	report.Scanner = ref + " (" + image.ID + ")"
+	return report, nil
+}
+
+// agentlessCheck audits the remote host without running anything on it.
+// Everything that can be derived from the Docker API is evaluated from
+// here; checks that need the host's files or processes are reported as
+// not applicable.
+func agentlessCheck() {
+	endpoint, err := newDockerEndpoint()
 	if err != nil {
-		client.RemoveImage(BattenDockerRepository)
-		fatalf("Failed to create container on host '%s'. Error: %v", *serverIP, err)
-	}
-			
-	// Start Batten container
-	binds := []string{"/var/run/docker.sock:/var/run/docker.sock"}
-	hostConfig := &docker.HostConfig{Binds: binds}
-	err = client.StartContainer(container.ID, hostConfig)
+		fatalf("Failed to connect to host '%s'. Error: %v", *serverIP, err)
+	}
+	client, err := endpoint.NewClient()
+	if err == nil {
+		err = client.Ping()
+	}
+	if err != nil {
+		fatalf("Failed to connect to host '%s'. Error: %v", *serverIP, err)
+	}
+
+	if err := batten.UseDockerEndpoint(endpoint); err != nil {
+		fatalf("Failed to connect to host '%s'. Error: %v", *serverIP, err)
+	}
+	runChecks(*serverIP, batten.RunCheckAgentless)
+}
+
+func remoteCheck() {
+	client, err := newDockerClient()
+
 	if err != nil {
-		cleanUp(client, container)
-		fatalf("Failed to start container on host '%s'. Error: %v", *serverIP, err)
-	}	
-	
-	// Wait for container scan to finish
-	colorPrint(ansi.Green, "Running scan on host '%s'...", *serverIP)
-	code, err := client.WaitContainer(container.ID)
-	
-	if err != nil || code != 0{
-		cleanUp(client, container)
-		fatalf("Container finished with errors. Host: '%s', code: '%d', error: %v", *serverIP, code, err)
-	}
-	
-	var logsBuf bytes.Buffer
-	// Print container logs
-	opts := docker.AttachToContainerOptions{
-		Container:    container.ID,
-		OutputStream: &logsBuf,
-		Stdout:       true,
-		Stderr:       true,
-		Logs:         true,
-	}
-	err = client.AttachToContainer(opts)
+		fatalf("Failed to connect to host '%s'. Error: %v", *serverIP, err)
+	}
+
+	scan := &remoteScan{client: client, host: *serverIP, progress: os.Stderr}
+
+	// Don't leave the scan running on the host when we are interrupted
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		colorPrint(ansi.Yellow, "Received %v, stopping the scan on host '%s'...", sig, scan.host)
+		scan.interrupt()
+		os.Exit(1)
+	}()
+
+	report, err := scan.run(*scanImage, *scanPull, *scanImageArchive)
+	scan.cleanUp()
+	signal.Stop(signals)
 	if err != nil {
-		cleanUp(client, container)
-		fatalf("Failed to attach to contianer output on host '%s'. Error: %v", *serverIP, err)
-	}
-	fmt.Printf("%s", logsBuf.String())
-	
-	// Cleanup - remove container and image
-	cleanUp(client, container)
-	
-	return err
+		fatalf("Scan failed on host '%s'. Error: %v", scan.host, err)
+	}
+	// the scan container runs every check, whatever the scanner version
+	// knows of the filters; only the selected ones are reported
+	report.Filter(checkFilter.filter())
+
+	colorPrint(ansi.Green, "Scan finished on host '%s': %d passed, %d failed, %d errors.", scan.host,
+		report.Count(cli.StatusPassed), report.Count(cli.StatusFailed), report.Count(cli.StatusError))
+	writeReport(report, *checkFormat, *checkOutput)
 }
 
+// remoteCleanup removes scan containers left behind on the host by scans
+// that could not clean up after themselves, such as ones that were killed.
+// Containers younger than `olderThan` may belong to a scan in progress and
+// are left alone.
+func remoteCleanup(olderThan time.Duration) {
+	var client *docker.Client
+	var err error
+	host := *serverIP
+	if host != "" {
+		client, err = newDockerClient()
+	} else {
+		// without --server, clean up the daemon local checks would audit
+		var endpoint *batten.Endpoint
+		if endpoint, err = batten.ResolveEndpoint(*dockerHost, *dockerContext); err == nil {
+			host = endpoint.Host
+			client, err = endpoint.NewClient()
+		}
+	}
+	if err != nil {
+		fatalf("Failed to connect to host '%s'. Error: %v", host, err)
+	}
+
+	containers, err := client.ListContainers(docker.ListContainersOptions{
+		All:     true,
+		Filters: map[string][]string{"label": {ScanContainerLabel}},
+	})
+	if err != nil {
+		fatalf("Failed to list containers on host '%s'. Error: %v", host, err)
+	}
+
+	var removed int
+	for _, c := range containers {
+		if time.Since(time.Unix(c.Created, 0)) < olderThan {
+			continue
+		}
+		err := client.RemoveContainer(docker.RemoveContainerOptions{ID: c.ID, RemoveVolumes: true, Force: true})
+		if err != nil {
+			colorPrint(ansi.Yellow, "Failed to remove container '%s' on host '%s'. Error: %v", c.ID, host, err)
+			continue
+		}
+		colorPrint(ansi.Green, "Removed scan container %v (%s).", c.Names, c.ID)
+		removed++
+	}
+	colorPrint(ansi.Green, "Removed %d scan containers from host '%s'.", removed, host)
+}
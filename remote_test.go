@@ -0,0 +1,80 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	docker "github.com/fsouza/go-dockerclient"
+	dtesting "github.com/fsouza/go-dockerclient/testing"
+)
+
+// An interrupt that comes while the scanner image is prepared stops the
+// scan before it creates its container.
+func TestRemoteScanInterruptedBeforeCreate(t *testing.T) {
+	var scan *remoteScan
+	server, err := dtesting.NewServer("127.0.0.1:0", nil, func(r *http.Request) {
+		if r.URL.Path == "/images/jerbi/batten/json" {
+			scan.interrupt()
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client, err := docker.NewClient(strings.TrimSuffix(server.URL(), "/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.PullImage(docker.PullImageOptions{Repository: "jerbi/batten"}, docker.AuthConfiguration{}); err != nil {
+		t.Fatal(err)
+	}
+	scan = &remoteScan{client: client, host: "test", progress: ioutil.Discard}
+
+	if _, err := scan.run("jerbi/batten", PullNever, ""); err != errScanInterrupted {
+		t.Errorf("expected the scan to be interrupted, got %v", err)
+	}
+	containers, err := client.ListContainers(docker.ListContainersOptions{All: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(containers) > 0 {
+		t.Errorf("expected no containers, got %+v", containers)
+	}
+}
+
+// An interrupt that comes while the scanner image is pulled removes the
+// image, which was not on the host before.
+func TestRemoteScanInterruptedWhilePulling(t *testing.T) {
+	var scan *remoteScan
+	inspected := 0
+	server, err := dtesting.NewServer("127.0.0.1:0", nil, func(r *http.Request) {
+		// the image is inspected before it is pulled, and once it is
+		if r.URL.Path == "/images/jerbi/batten:latest/json" {
+			if inspected++; inspected == 2 {
+				scan.interrupt()
+			}
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client, err := docker.NewClient(strings.TrimSuffix(server.URL(), "/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scan = &remoteScan{client: client, host: "test", progress: ioutil.Discard}
+
+	if _, err := scan.run("jerbi/batten:latest", PullAlways, ""); err == nil {
+		t.Error("expected the scan to fail")
+	}
+	images, err := client.ListImages(docker.ListImagesOptions{All: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(images) > 0 {
+		t.Errorf("expected no images, got %+v", images)
+	}
+}